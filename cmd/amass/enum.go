@@ -19,6 +19,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
@@ -79,6 +80,7 @@ type enumArgs struct {
 		AltWordlist      format.ParseStrings
 		Blacklist        string
 		BruteWordlist    format.ParseStrings
+		BySource         string
 		ConfigFile       string
 		Directory        string
 		Domains          format.ParseStrings
@@ -86,9 +88,13 @@ type enumArgs struct {
 		IncludedSrcs     string
 		JSONOutput       string
 		LogFile          string
+		Metrics          string
+		Pivots           string
+		Unresolved       string
 		Names            format.ParseStrings
 		Resolvers        format.ParseStrings
 		ScriptsDirectory string
+		Stream           string
 		TermOut          string
 	}
 }
@@ -142,10 +148,15 @@ func defineEnumFilepathFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.StringVar(&args.Filepaths.IncludedSrcs, "if", "", "Path to a file providing data sources to include")
 	enumFlags.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
 	enumFlags.StringVar(&args.Filepaths.LogFile, "log", "", "Path to the log file where errors will be written")
+	enumFlags.StringVar(&args.Filepaths.Metrics, "metrics", "", "Path to the JSON file where data source metrics will be written")
 	enumFlags.Var(&args.Filepaths.Names, "nf", "Path to a file providing already known subdomain names (from other tools/sources)")
+	enumFlags.StringVar(&args.Filepaths.BySource, "by-source", "", "Path to the JSON file where the discovered names will be written grouped by data source")
+	enumFlags.StringVar(&args.Filepaths.Pivots, "pivots", "", "Path to the text file where out of scope names related to the scope will be written")
 	enumFlags.Var(&args.Filepaths.Resolvers, "rf", "Path to a file providing preferred DNS resolvers")
 	enumFlags.StringVar(&args.Filepaths.ScriptsDirectory, "scripts", "", "Path to a directory containing ADS scripts")
+	enumFlags.StringVar(&args.Filepaths.Stream, "stream", "", "Path to the JSON Lines file where names will be written as they are found")
 	enumFlags.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
+	enumFlags.StringVar(&args.Filepaths.Unresolved, "unresolved", "", "Path to the text file where data source names that never resolved will be written")
 }
 
 func runEnumCommand(clArgs []string) {
@@ -170,6 +181,18 @@ func runEnumCommand(clArgs []string) {
 	// Start handling the log messages
 	go writeLogsAndMessages(rLog, logfile, args.Options.Verbose)
 
+	// Names are appended to the stream file, so it can be followed while the enumeration runs
+	if args.Filepaths.Stream != "" {
+		f, err := os.OpenFile(args.Filepaths.Stream, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to open the name stream file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		cfg.NameStream = f
+	}
+
 	// Create the System that will provide architecture to this enumeration
 	sys, err := systems.NewLocalSystem(cfg)
 	if err != nil {
@@ -179,7 +202,15 @@ func runEnumCommand(clArgs []string) {
 	defer sys.Shutdown()
 	sys.SetDataSources(datasrcs.GetAllSources(sys))
 	// Expand data source category names into the associated source names
-	cfg.SourceFilter.Sources = expandCategoryNames(cfg.SourceFilter.Sources, generateCategoryMap(sys))
+	categories := generateCategoryMap(sys)
+	cfg.SourceFilter.Sources = expandCategoryNames(cfg.SourceFilter.Sources, categories)
+	cfg.SourceFilter.Included = expandCategoryNames(cfg.SourceFilter.Included, categories)
+	cfg.SourceFilter.Excluded = expandCategoryNames(cfg.SourceFilter.Excluded, categories)
+
+	// The yield order uses the names recorded by the metrics report of the previous enumerations
+	if cfg.SourceOrder == config.SourceOrderYield && args.Filepaths.Metrics != "" {
+		cfg.SourceYield = loadMetricsReport(args.Filepaths.Metrics)
+	}
 
 	// Setup the new enumeration
 	e := enum.NewEnumeration(cfg, sys)
@@ -245,6 +276,22 @@ func runEnumCommand(clArgs []string) {
 	// Let all the goroutines know that the enumeration has finished
 	close(done)
 	wg.Wait()
+	// Save the report of how each data source performed
+	if args.Filepaths.Metrics != "" {
+		saveMetricsReport(e.Sys, args.Filepaths.Metrics)
+	}
+	// Save the names that the data sources provided without being resolved
+	if args.Filepaths.Unresolved != "" {
+		saveUnresolvedNames(e.Sys, args.Filepaths.Unresolved)
+	}
+	// Save the discovered names grouped by the data sources that found them
+	if args.Filepaths.BySource != "" {
+		saveSourceGroups(e.Sys, args.Filepaths.BySource)
+	}
+	// Save the names outside the scope that could be used to expand it
+	if args.Filepaths.Pivots != "" {
+		savePivotCandidates(e.Sys, args.Filepaths.Pivots)
+	}
 
 	//e.Graph.DumpGraph()
 	// If necessary, handle graph database migration
@@ -457,6 +504,95 @@ func saveTextOutput(e *enum.Enumeration, args *enumArgs, output chan *requests.O
 	}
 }
 
+func saveMetricsReport(sys systems.System, path string) {
+	report, err := sys.Metrics().JSON()
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to generate the data source metrics: %v\n", err)
+		return
+	}
+
+	if err := ioutil.WriteFile(path, report, 0644); err != nil {
+		r.Fprintf(color.Error, "Failed to write the data source metrics file: %v\n", err)
+	}
+}
+
+func loadMetricsReport(path string) map[string]int {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		// The report does not exist before the first enumeration
+		return nil
+	}
+
+	yield, err := systems.ReportYield(data)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to load the data source metrics file: %v\n", err)
+	}
+	return yield
+}
+
+func saveUnresolvedNames(sys systems.System, path string) {
+	c := sys.Candidates()
+	if c == nil {
+		return
+	}
+
+	unresolved := c.Unresolved()
+	names := make([]string, 0, len(unresolved))
+	for name := range unresolved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "[%s] %s\n", strings.Join(unresolved[name], ", "), name)
+	}
+
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		r.Fprintf(color.Error, "Failed to write the unresolved names file: %v\n", err)
+	}
+}
+
+func saveSourceGroups(sys systems.System, path string) {
+	g := sys.SourceGroups()
+	if g == nil {
+		return
+	}
+
+	data, err := json.MarshalIndent(g.Groups(), "", "    ")
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to encode the names grouped by data source: %v\n", err)
+		return
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		r.Fprintf(color.Error, "Failed to write the names grouped by data source: %v\n", err)
+	}
+}
+
+func savePivotCandidates(sys systems.System, path string) {
+	p := sys.Pivots()
+	if p == nil {
+		return
+	}
+
+	candidates := p.Candidates(sys.Config().Domains())
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "[%s] %s\n", strings.Join(candidates[name], ", "), name)
+	}
+
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		r.Fprintf(color.Error, "Failed to write the pivot candidates file: %v\n", err)
+	}
+}
+
 func saveJSONOutput(e *enum.Enumeration, args *enumArgs, output chan *requests.Output, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -504,6 +640,9 @@ func processOutput(e *enum.Enumeration, outputs []chan *requests.Output, done ch
 			if !e.Config.IsDomainInScope(o.Name) {
 				continue
 			}
+			if g := e.Sys.SourceGroups(); g != nil {
+				g.Add(o)
+			}
 
 			for _, ch := range outputs {
 				ch <- o
@@ -597,24 +736,18 @@ func writeLogsAndMessages(logs *io.PipeReader, logfile string, verbose bool) {
 // Obtain parameters from provided input files
 func processEnumInputFiles(args *enumArgs) error {
 	if args.Options.BruteForcing && len(args.Filepaths.BruteWordlist) > 0 {
-		for _, f := range args.Filepaths.BruteWordlist {
-			list, err := config.GetListFromFile(f)
-			if err != nil {
-				return fmt.Errorf("Failed to parse the brute force wordlist file: %v", err)
-			}
-
-			args.BruteWordList.InsertMany(list...)
+		list, err := config.GetListFromFiles(args.Filepaths.BruteWordlist...)
+		if err != nil {
+			return fmt.Errorf("Failed to parse the brute force wordlist files: %v", err)
 		}
+		args.BruteWordList.InsertMany(list...)
 	}
 	if !args.Options.NoAlts && len(args.Filepaths.AltWordlist) > 0 {
-		for _, f := range args.Filepaths.AltWordlist {
-			list, err := config.GetListFromFile(f)
-			if err != nil {
-				return fmt.Errorf("Failed to parse the alterations wordlist file: %v", err)
-			}
-
-			args.AltWordList.InsertMany(list...)
+		list, err := config.GetListFromFiles(args.Filepaths.AltWordlist...)
+		if err != nil {
+			return fmt.Errorf("Failed to parse the alterations wordlist files: %v", err)
 		}
+		args.AltWordList.InsertMany(list...)
 	}
 	if args.Filepaths.Blacklist != "" {
 		list, err := config.GetListFromFile(args.Filepaths.Blacklist)
@@ -672,6 +805,15 @@ func processEnumInputFiles(args *enumArgs) error {
 
 // Setup the amass enumeration settings
 func (e enumArgs) OverrideConfig(conf *config.Config) error {
+	if e.Filepaths.Unresolved != "" {
+		conf.CollectUnresolved = true
+	}
+	if e.Filepaths.Pivots != "" {
+		conf.CollectPivots = true
+	}
+	if e.Filepaths.BySource != "" {
+		conf.GroupBySource = true
+	}
 	if len(e.Addresses) > 0 {
 		conf.Addresses = e.Addresses
 	}
@@ -736,6 +878,11 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 		conf.MonitorResolverRate = false
 	}
 
+	if len(e.Included) > 0 || len(e.Excluded) > 0 {
+		// The command-line arguments take the place of the config file lists
+		conf.SourceFilter.Included = nil
+		conf.SourceFilter.Excluded = nil
+	}
 	if len(e.Included) > 0 {
 		conf.SourceFilter.Include = true
 		// Check if brute forcing and alterations should be added
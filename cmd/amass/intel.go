@@ -386,6 +386,11 @@ func (i intelArgs) OverrideConfig(conf *config.Config) error {
 		conf.MonitorResolverRate = false
 	}
 
+	if len(i.Included) > 0 || len(i.Excluded) > 0 {
+		// The command-line arguments take the place of the config file lists
+		conf.SourceFilter.Included = nil
+		conf.SourceFilter.Excluded = nil
+	}
 	if len(i.Included) > 0 {
 		conf.SourceFilter.Include = true
 		conf.SourceFilter.Sources = i.Included.Slice()
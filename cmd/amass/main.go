@@ -147,7 +147,7 @@ func GetAllSourceInfo(cfg *config.Config) []string {
 	}
 	defer sys.Shutdown()
 
-	srcs := datasrcs.SelectedDataSources(cfg, datasrcs.GetAllSources(sys))
+	srcs := datasrcs.SelectedDataSources(cfg, datasrcs.AllSources(sys))
 	sys.SetDataSources(srcs)
 
 	return DataSourceInfo(srcs, sys)
@@ -25,13 +25,11 @@ func (c *Config) loadBruteForceSettings(cfg *ini.File) error {
 	c.MinForRecursive = bruteforce.Key("minimum_for_recursive").MustInt(0)
 
 	if bruteforce.HasKey("wordlist_file") {
-		for _, wordlist := range bruteforce.Key("wordlist_file").ValueWithShadows() {
-			list, err := GetListFromFile(wordlist)
-			if err != nil {
-				return fmt.Errorf("Unable to load the file in the bruteforce wordlist_file setting: %s: %v", wordlist, err)
-			}
-			c.Wordlist = append(c.Wordlist, list...)
+		list, err := GetListFromFiles(bruteforce.Key("wordlist_file").ValueWithShadows()...)
+		if err != nil {
+			return fmt.Errorf("Unable to load the files in the bruteforce wordlist_file setting: %v", err)
 		}
+		c.Wordlist = append(c.Wordlist, list...)
 	}
 
 	c.Wordlist = stringset.Deduplicate(c.Wordlist)
@@ -55,15 +53,14 @@ func (c *Config) loadAlterationSettings(cfg *ini.File) error {
 	c.AddNumbers = alterations.Key("add_numbers").MustBool(true)
 	c.MinForWordFlip = alterations.Key("minimum_for_word_flip").MustInt(2)
 	c.EditDistance = alterations.Key("edit_distance").MustInt(1)
+	c.MaxAltsPerName = alterations.Key("maximum_per_name").MustInt(0)
 
 	if alterations.HasKey("wordlist_file") {
-		for _, wordlist := range alterations.Key("wordlist_file").ValueWithShadows() {
-			list, err := GetListFromFile(wordlist)
-			if err != nil {
-				return fmt.Errorf("Unable to load the file in the alterations wordlist_file setting: %s: %v", wordlist, err)
-			}
-			c.AltWordlist = append(c.AltWordlist, list...)
+		list, err := GetListFromFiles(alterations.Key("wordlist_file").ValueWithShadows()...)
+		if err != nil {
+			return fmt.Errorf("Unable to load the files in the alterations wordlist_file setting: %v", err)
 		}
+		c.AltWordlist = append(c.AltWordlist, list...)
 	}
 
 	c.AltWordlist = stringset.Deduplicate(c.AltWordlist)
@@ -13,12 +13,14 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	_ "github.com/OWASP/Amass/v3/config/statik" // The content being embedded into the binary
 	"github.com/OWASP/Amass/v3/wordlist"
@@ -57,12 +59,54 @@ type Config struct {
 	// Logger for error messages
 	Log *log.Logger
 
+	// The transport used for the web requests of the System, which replaces the network when it's not nil
+	Transport http.RoundTripper `ini:"-"`
+
+	// The names provided by the data sources are written to NameStream as JSON Lines when it's not nil
+	NameStream io.Writer `ini:"-"`
+
 	// The directory that stores the bolt db and other files created
 	Dir string `ini:"output_directory"`
 
 	// Alternative directory for scripts provided by the user
 	ScriptsDirectory string `ini:"scripts_directory"`
 
+	// The URL of the HTTP or SOCKS5 proxy used for the web requests
+	Proxy string `ini:"proxy"`
+
+	// The idle connections kept open for reuse by the web requests, in total and for each host,
+	// and the seconds an idle connection is kept open, where zero values select the defaults
+	MaxIdleConns        int `ini:"max_idle_connections"`
+	MaxIdleConnsPerHost int `ini:"max_idle_connections_per_host"`
+	IdleConnTimeout     int `ini:"idle_connection_timeout"`
+
+	// Will DNS names embedded within longer tokens, such as email addresses, be rejected?
+	StrictNameMatching bool `ini:"strict_name_matching"`
+
+	// Will DNS names without a dot and TLD, such as the hostnames of internal networks, be accepted?
+	AllowSingleLabelNames bool `ini:"allow_single_label_names"`
+
+	// The User-Agent strings rotated through by the web requests
+	UserAgents []string
+
+	// Will the web crawler honor robots.txt files? Enabling this can reduce the names discovered
+	HonorRobotsTxt bool `ini:"honor_robots_txt"`
+
+	// The seconds allowed for each web crawl, the requests each crawl makes concurrently and the
+	// milliseconds between the requests of a crawl, where zero values select the crawler defaults
+	CrawlTimeout            int `ini:"crawl_timeout"`
+	CrawlConcurrentRequests int `ini:"crawl_concurrent_requests"`
+	CrawlRequestDelay       int `ini:"crawl_request_delay"`
+
+	// The budget of requests for each web crawl, unless the value is zero
+	CrawlMaxRequests int `ini:"crawl_max_requests"`
+
+	// The maximum number of web crawls performed concurrently
+	MaxConcurrentCrawls int `ini:"maximum_concurrent_crawls"`
+
+	// The maximum number of DNS names resolved concurrently, unless the value is zero
+	MaxConcurrentResolutions int `ini:"maximum_concurrent_resolutions"`
+
 	// Use a local graph database
 	LocalDatabase bool
 
@@ -109,6 +153,9 @@ type Config struct {
 	EditDistance   int
 	AltWordlist    []string
 
+	// The maximum number of alterations generated from each resolved name, unless the value is zero
+	MaxAltsPerName int
+
 	// Only access the data sources for names and return results?
 	Passive bool
 
@@ -118,15 +165,73 @@ type Config struct {
 	// A blacklist of subdomain names that will not be investigated
 	Blacklist []string
 
+	// The names and regular expressions for names that are dropped before any data source provides them.
+	// Names beginning with the "*." label block every subdomain of the remaining name
+	BlockedNames    []string
+	BlockedPatterns []*regexp.Regexp
+
 	// A list of data sources that should not be utilized
 	SourceFilter struct {
 		Include bool // true = include, false = exclude
 		Sources []string
+		// When either list is provided, the fields above are ignored. The Excluded sources
+		// are removed from all the data sources and the Included sources are then added back
+		Included []string
+		Excluded []string
 	}
 
 	// The minimum number of minutes that data source responses will be reused
 	MinimumTTL int
 
+	// Will names already provided by another data source be dropped?
+	DeduplicateSourceNames bool
+
+	// The maximum number of names each data source can provide, unless the value is zero
+	MaxSourceNames int
+
+	// Will the names provided by the data sources that never resolve be collected?
+	CollectUnresolved bool `ini:"collect_unresolved_names"`
+
+	// Will the names discovered by the enumeration be grouped by the data sources that found them?
+	GroupBySource bool `ini:"group_names_by_source"`
+
+	// Will the names provided by the data sources outside the scope be collected as pivot candidates,
+	// and will every such name be collected, instead of only those related to the scope?
+	CollectPivots bool `ini:"collect_pivot_candidates"`
+	PivotAllNames bool `ini:"pivot_all_names"`
+
+	// The number of times failed data source requests are retried and the base delay between them
+	RequestRetries    int
+	RequestRetryDelay time.Duration
+
+	// The minimum delay between the web requests of all the data sources, and the largest
+	// random duration added to each delay, where zero values send the requests without delay
+	RequestDelay  time.Duration
+	RequestJitter time.Duration
+
+	// The longest Retry-After delay requested by a data source service that will be honored
+	MaxRetryAfter time.Duration
+
+	// The consecutive failed requests that cause further requests to a data source to be skipped, unless
+	// the value is zero, and the delay before a single request tests whether the data source recovered
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+
+	// The largest response body in bytes read from a web request, unless the value is zero
+	MaxResponseSize int64
+
+	// The directory where the raw responses of the web requests are archived, unless the value is empty
+	ResponseArchive string
+
+	// The longest period the data sources are queried during an enumeration, unless the value is zero
+	SourcesTimeout time.Duration
+
+	// The order that the data sources receive the requests, which is one of the SourceOrder constants
+	SourceOrder string
+
+	// The names provided by each data source during the previous enumerations, which the yield order uses
+	SourceYield map[string]int `ini:"-"`
+
 	// Type of DNS records to query for
 	RecordTypes []string
 
@@ -134,6 +239,16 @@ type Config struct {
 	Resolvers           []string
 	MonitorResolverRate bool
 
+	// The DNS-over-HTTPS server used by the data sources that resolve names, and whether
+	// the resolvers above answer the queries when the server fails
+	DoHServer   string
+	DoHFallback bool
+
+	// The number of unlikely names queried to detect each DNS wildcard, and the behavior
+	// for the names that match a DNS wildcard, which are suppressed unless the mode is changed
+	WildcardTests int
+	WildcardMode  string
+
 	// Option for verbose logging and output
 	Verbose bool
 
@@ -165,7 +280,13 @@ func NewConfig() *Config {
 		MinForWordFlip: 2,
 		EditDistance:   1,
 		Recursive:      true,
-		MinimumTTL:     1440,
+		// The data source settings
+		MinimumTTL:             1440,
+		DeduplicateSourceNames: true,
+		RequestRetries:         2,
+		RequestRetryDelay:      time.Second,
+		MaxRetryAfter:          time.Minute,
+		MaxConcurrentCrawls:    50,
 	}
 
 	c.calcDNSQueriesMax()
@@ -194,6 +315,15 @@ func (c *Config) CheckSettings() error {
 	if c.Passive && c.Active {
 		return errors.New("Active enumeration cannot be performed without DNS resolution")
 	}
+	if err := c.checkSourceFilter(); err != nil {
+		return err
+	}
+	if c.Proxy != "" {
+		if u, err := url.Parse(c.Proxy); err != nil || u.Host == "" ||
+			(u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return fmt.Errorf("The proxy URL %s is not valid", c.Proxy)
+		}
+	}
 	if c.Alterations {
 		if len(c.AltWordlist) == 0 {
 			c.AltWordlist, err = getWordlistByFS("/alterations.txt")
@@ -215,12 +345,9 @@ func (c *Config) CheckSettings() error {
 	return err
 }
 
-// LoadSettings parses settings from an .ini file and assigns them to the Config.
+// LoadSettings parses settings from an .ini, .json or .toml file and assigns them to the Config.
 func (c *Config) LoadSettings(path string) error {
-	cfg, err := ini.LoadSources(ini.LoadOptions{
-		Insensitive:  true,
-		AllowShadows: true,
-	}, path)
+	cfg, err := loadSettingsFile(path)
 	if err != nil {
 		return fmt.Errorf("Failed to load the configuration file: %v", err)
 	}
@@ -242,10 +369,12 @@ func (c *Config) LoadSettings(path string) error {
 	loads := []func(cfg *ini.File) error{
 		c.loadResolverSettings,
 		c.loadScopeSettings,
+		c.loadBlocklistSettings,
 		c.loadAlterationSettings,
 		c.loadBruteForceSettings,
 		c.loadDatabaseSettings,
 		c.loadDataSourceSettings,
+		c.loadUserAgentSettings,
 	}
 	for _, load := range loads {
 		if err := load(cfg); err != nil {
@@ -256,6 +385,16 @@ func (c *Config) LoadSettings(path string) error {
 	return nil
 }
 
+func (c *Config) loadUserAgentSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("user_agents")
+	if err != nil {
+		return nil
+	}
+
+	c.UserAgents = stringset.Deduplicate(sec.Key("user_agent").ValueWithShadows())
+	return nil
+}
+
 // AcquireConfig populates the Config struct provided by the config argument.
 func AcquireConfig(dir, file string, config *Config) error {
 	var err error
@@ -331,6 +470,21 @@ func GetListFromFile(path string) ([]string, error) {
 	return s, err
 }
 
+// GetListFromFiles reads each of the wordlist text or gzip files and returns the merged
+// slice of words, without duplicates. The path of a file that cannot be read is reported.
+func GetListFromFiles(paths ...string) ([]string, error) {
+	words := stringset.New()
+
+	for _, path := range paths {
+		list, err := GetListFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		words.InsertMany(list...)
+	}
+	return words.Slice(), nil
+}
+
 func getWordlistByFS(path string) ([]string, error) {
 	fsOnce.Do(openTheFS)
 
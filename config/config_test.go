@@ -4,10 +4,16 @@
 package config
 
 import (
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+
+	"github.com/go-ini/ini"
 )
 
 func TestCheckSettings(t *testing.T) {
@@ -19,7 +25,95 @@ func TestCheckSettings(t *testing.T) {
 		t.Errorf("Error checking settings.\n%v", err)
 	}
 
+	c.Proxy = "socks5://127.0.0.1:9050"
+	if err := c.CheckSettings(); err != nil {
+		t.Errorf("CheckSettings returned an error for a valid proxy URL: %v", err)
+	}
+
+	c.Proxy = "ftp://127.0.0.1"
+	if err := c.CheckSettings(); err == nil {
+		t.Errorf("CheckSettings failed to report an error for an invalid proxy URL")
+	}
+}
+
+func TestLoadUserAgentSettings(t *testing.T) {
+	c := NewConfig()
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[user_agents]
+		user_agent = agent1
+		user_agent = agent2
+		`),
+	)
+
+	if err := c.loadUserAgentSettings(cfg); err != nil {
+		t.Errorf("Failed to parse the user agent settings: %v", err)
+	}
+
+	sort.Strings(c.UserAgents)
+	if !reflect.DeepEqual(c.UserAgents, []string{"agent1", "agent2"}) {
+		t.Errorf("Failed to load the user agents: %v", c.UserAgents)
+	}
+}
+
+func TestLoadDoHSettings(t *testing.T) {
+	c := NewConfig()
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[resolvers]
+		doh_server = cloudflare
+		doh_fallback = false
+		`),
+	)
+
+	if err := c.loadResolverSettings(cfg); err != nil {
+		t.Errorf("Failed to parse the resolver settings: %v", err)
+	}
+	if c.DoHServer != "cloudflare" || c.DoHFallback {
+		t.Errorf("Failed to load the DNS-over-HTTPS settings: %s, %t", c.DoHServer, c.DoHFallback)
+	}
+}
+
+func TestLoadWildcardSettings(t *testing.T) {
+	c := NewConfig()
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[resolvers]
+		resolver = 8.8.8.8
+		wildcard_tests = 5
+		wildcard_mode = Report
+		`),
+	)
+
+	if err := c.loadResolverSettings(cfg); err != nil {
+		t.Errorf("Failed to parse the resolver settings: %v", err)
+	}
+	if c.WildcardTests != 5 || c.WildcardMode != WildcardModeReport {
+		t.Errorf("Failed to load the DNS wildcard settings: %d, %s", c.WildcardTests, c.WildcardMode)
+	}
+
+	cfg, _ = ini.LoadSources(ini.LoadOptions{Insensitive: true}, []byte(`
+		[resolvers]
+		resolver = 8.8.8.8
+		wildcard_mode = ignore
+		`))
+	if err := c.loadResolverSettings(cfg); err == nil {
+		t.Errorf("Expected an error for the unsupported wildcard mode")
+	}
 }
+
 func TestDomainRegex(t *testing.T) {
 	c := NewConfig()
 	got := c.DomainRegex("owasp.org")
@@ -103,6 +197,51 @@ func TestBlacklist(t *testing.T) {
 	}
 }
 
+func TestBlocklist(t *testing.T) {
+	c := NewConfig()
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[scope.blocklist]
+		name = internal.owasp.org
+		name = *.cdn.cloudflare.net
+		pattern = ^ip-[0-9-]+[.]
+		`),
+	)
+
+	if err := c.loadBlocklistSettings(cfg); err != nil {
+		t.Fatalf("Failed to parse the blocklist settings: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		blocked bool
+	}{
+		{"internal.owasp.org", true},
+		{"www.internal.owasp.org", false},
+		{"www.owasp.org.cdn.cloudflare.net", true},
+		{"cdn.cloudflare.net", false},
+		{"ip-10-0-0-1.owasp.org", true},
+		{"vip-10.owasp.org", false},
+	}
+	for _, test := range tests {
+		if blocked := c.BlockedBy(test.name) != ""; blocked != test.blocked {
+			t.Errorf("%s: expected blocked to be %t", test.name, test.blocked)
+		}
+	}
+
+	cfg, _ = ini.LoadSources(ini.LoadOptions{Insensitive: true}, []byte(`
+		[scope.blocklist]
+		pattern = ([a-z
+		`))
+	if err := NewConfig().loadBlocklistSettings(cfg); err == nil {
+		t.Errorf("Expected an error for the invalid blocklist pattern")
+	}
+}
+
 func TestLoadSettings(t *testing.T) {
 	c := NewConfig()
 	path := "../examples/config.ini"
@@ -110,3 +249,141 @@ func TestLoadSettings(t *testing.T) {
 		t.Errorf("Config file failed to load.")
 	}
 }
+
+func TestLoadStructuredSettings(t *testing.T) {
+	files := map[string]string{
+		"config.ini": `
+mode = active
+maximum_dns_queries = 2000
+
+[scope]
+[scope.domains]
+domain = example.com
+domain = example.org
+
+[data_sources]
+minimum_ttl = 60
+
+[data_sources.disabled]
+data_source = CommonCrawl
+data_source = Crtsh
+
+[data_sources.AlienVault]
+ttl = 4320
+[data_sources.AlienVault.Credentials]
+apikey = fake
+`,
+		"config.json": `{
+	"mode": "active",
+	"maximum_dns_queries": 2000,
+	"scope": {"domains": {"domain": ["example.com", "example.org"]}},
+	"data_sources": {
+		"minimum_ttl": 60,
+		"disabled": {"data_source": ["CommonCrawl", "Crtsh"]},
+		"AlienVault": {"ttl": 4320, "Credentials": {"apikey": "fake"}}
+	}
+}`,
+		"config.toml": `
+mode = "active"
+maximum_dns_queries = 2000
+
+[scope.domains]
+domain = ["example.com", "example.org"]
+
+[data_sources]
+minimum_ttl = 60
+
+[data_sources.disabled]
+data_source = ["CommonCrawl", "Crtsh"]
+
+[data_sources.AlienVault]
+ttl = 4320
+[data_sources.AlienVault.Credentials]
+apikey = "fake"
+`,
+	}
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+
+		c := NewConfig()
+		if err := c.LoadSettings(path); err != nil {
+			t.Errorf("%s failed to load: %v", name, err)
+			continue
+		}
+		if !c.Active || c.MaxDNSQueries != 2000 || c.MinimumTTL != 60 {
+			t.Errorf("%s: Failed to load the general settings", name)
+		}
+
+		domains := c.Domains()
+		sort.Strings(domains)
+		if !reflect.DeepEqual(domains, []string{"example.com", "example.org"}) {
+			t.Errorf("%s: Failed to load the scope domains: %v", name, domains)
+		}
+
+		sources := c.SourceFilter.Sources
+		sort.Strings(sources)
+		if c.SourceFilter.Include || !reflect.DeepEqual(sources, []string{"commoncrawl", "crtsh"}) {
+			t.Errorf("%s: Failed to load the disabled data sources: %v", name, sources)
+		}
+
+		dsc := c.GetDataSourceConfig("AlienVault")
+		if dsc == nil || dsc.TTL != 4320 {
+			t.Errorf("%s: Failed to load the data source settings", name)
+		} else if creds := dsc.GetCredentials(); creds == nil || creds.Key != "fake" {
+			t.Errorf("%s: Failed to load the data source credentials", name)
+		}
+	}
+}
+
+func TestLoadSettingsSniffsJSON(t *testing.T) {
+	f, err := ioutil.TempFile("", "amass")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, _ = f.WriteString(`{"mode": "passive", "data_sources": {}}`)
+	f.Close()
+
+	c := NewConfig()
+	if err := c.LoadSettings(f.Name()); err != nil || !c.Passive {
+		t.Errorf("Failed to detect the JSON content of the configuration file: %v", err)
+	}
+}
+
+func TestGetListFromFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wordlists")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	common := filepath.Join(dir, "common.txt")
+	target := filepath.Join(dir, "target.txt")
+	_ = ioutil.WriteFile(common, []byte("www\nmail\napi\n"), 0644)
+	_ = ioutil.WriteFile(target, []byte("api\nstaging\n"), 0644)
+
+	words, err := GetListFromFiles(common, target)
+	if err != nil {
+		t.Fatalf("Failed to load the wordlists: %v", err)
+	}
+	sort.Strings(words)
+	if !reflect.DeepEqual(words, []string{"api", "mail", "staging", "www"}) {
+		t.Errorf("The wordlists were not merged and deduplicated: %v", words)
+	}
+
+	missing := filepath.Join(dir, "missing.txt")
+	if _, err := GetListFromFiles(common, missing); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("The error for the missing wordlist did not name the file: %v", err)
+	}
+}
@@ -4,19 +4,46 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"regexp"
 	"strings"
+	"time"
 
-	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
 )
 
+// The strategies for ordering the data sources that receive the requests of an enumeration.
+const (
+	// The data sources are ordered by name
+	SourceOrderStatic = "static"
+	// The data sources with a higher priority option are first
+	SourceOrderPriority = "priority"
+	// The data sources are ordered by priority, and then by the names they provided according to the metrics
+	SourceOrderYield = "yield"
+)
+
 // DataSourceConfig contains the configurations specific to a data source.
 type DataSourceConfig struct {
-	Name  string
-	TTL   int `ini:"ttl"`
-	creds map[string]*Credentials
+	Name           string
+	TTL            int      `ini:"ttl"`
+	RateLimit      float64  `ini:"rate_limit"`
+	MaxResults     int      `ini:"max_results"`
+	Timeout        int      `ini:"timeout"`
+	Retries        int      `ini:"retries"` // Negative when the request_retries option applies
+	Priority       int      `ini:"priority"`
+	History        bool     `ini:"history"`
+	FlexSearch     bool     `ini:"flex_search"`
+	IncludeExpired bool     `ini:"include_expired"`
+	KeepWildcards  bool     `ini:"keep_wildcards"`
+	Timestamps     bool     `ini:"timestamps"`
+	IncludeAddrs   bool     `ini:"include_addresses"`
+	MaxNames       int      `ini:"max_names"`
+	URL            string   `ini:"url"`
+	Datasets       []string `ini:"datasets"`
+	RecordTypes    []string `ini:"record_types"`
+	creds          map[string]*Credentials
 }
 
 // Credentials contains values required for authenticating with web APIs.
@@ -43,7 +70,7 @@ func (c *Config) GetDataSourceConfig(source string) *DataSourceConfig {
 	}
 
 	if _, found := c.datasrcConfigs[key]; !found {
-		c.datasrcConfigs[key] = &DataSourceConfig{Name: key}
+		c.datasrcConfigs[key] = &DataSourceConfig{Name: key, Retries: -1}
 	}
 
 	return c.datasrcConfigs[key]
@@ -75,6 +102,77 @@ func (dsc *DataSourceConfig) GetCredentials() *Credentials {
 	return nil
 }
 
+// SourceFilterRegexp returns the case-insensitive regular expression of a data source filter entry
+// enclosed in slashes, such as /^Cert/, or nil when the entry is a data source name.
+func SourceFilterRegexp(entry string) (*regexp.Regexp, error) {
+	entry = strings.TrimSpace(entry)
+	if len(entry) < 2 || !strings.HasPrefix(entry, "/") || !strings.HasSuffix(entry, "/") {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile("(?i)" + entry[1:len(entry)-1])
+	if err != nil {
+		return nil, fmt.Errorf("The data source filter entry %s is not a valid regular expression: %v", entry, err)
+	}
+	return re, nil
+}
+
+// Drops the invalid regular expressions from the data source filter entries, so the remaining
+// entries are still applied. A warning is written to the log for each entry dropped. An error is
+// returned when every entry of an include list is dropped, since the list would then select all
+// the data sources instead of the few requested.
+func (c *Config) checkSourceFilter() error {
+	f := &c.SourceFilter
+
+	sources := c.validSourceFilterEntries(f.Sources)
+	if f.Include && len(f.Sources) > 0 && len(sources) == 0 {
+		return errors.New("None of the included data source filter entries are valid")
+	}
+	f.Sources = sources
+
+	included := c.validSourceFilterEntries(f.Included)
+	if len(f.Included) > 0 && len(included) == 0 {
+		return errors.New("None of the included data source filter entries are valid")
+	}
+	f.Included = included
+
+	f.Excluded = c.validSourceFilterEntries(f.Excluded)
+	return nil
+}
+
+func (c *Config) validSourceFilterEntries(entries []string) []string {
+	var valid []string
+
+	for _, entry := range entries {
+		if _, err := SourceFilterRegexp(entry); err != nil {
+			c.Log.Printf("%v, so the entry was ignored", err)
+			continue
+		}
+		valid = append(valid, entry)
+	}
+	return valid
+}
+
+// Removes the duplicate data source filter entries and keeps the first occurrence of each. Only
+// the data source names are lowercased, since the case matters in some regular expressions.
+func deduplicateSourceFilter(entries []string) []string {
+	var unique []string
+	seen := make(map[string]struct{})
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if re, err := SourceFilterRegexp(entry); err == nil && re == nil {
+			entry = strings.ToLower(entry)
+		}
+		if _, found := seen[entry]; entry == "" || found {
+			continue
+		}
+		seen[entry] = struct{}{}
+		unique = append(unique, entry)
+	}
+	return unique
+}
+
 func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 	sec, err := cfg.GetSection("data_sources")
 	if err != nil {
@@ -87,15 +185,103 @@ func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 		}
 	}
 
+	if sec.HasKey("request_retries") {
+		if retries, err := sec.Key("request_retries").Int(); err == nil {
+			c.RequestRetries = retries
+		}
+	}
+
+	if sec.HasKey("retry_delay") {
+		if delay, err := sec.Key("retry_delay").Int(); err == nil {
+			c.RequestRetryDelay = time.Duration(delay) * time.Millisecond
+		}
+	}
+
+	if sec.HasKey("request_delay") {
+		if delay, err := sec.Key("request_delay").Int(); err == nil {
+			c.RequestDelay = time.Duration(delay) * time.Millisecond
+		}
+	}
+
+	if sec.HasKey("request_jitter") {
+		if jitter, err := sec.Key("request_jitter").Int(); err == nil {
+			c.RequestJitter = time.Duration(jitter) * time.Millisecond
+		}
+	}
+
+	if sec.HasKey("max_retry_after") {
+		if secs, err := sec.Key("max_retry_after").Int(); err == nil {
+			c.MaxRetryAfter = time.Duration(secs) * time.Second
+		}
+	}
+
+	if sec.HasKey("circuit_breaker_threshold") {
+		if num, err := sec.Key("circuit_breaker_threshold").Int(); err == nil {
+			c.CircuitBreakerThreshold = num
+		}
+	}
+
+	if sec.HasKey("circuit_breaker_cooldown") {
+		if secs, err := sec.Key("circuit_breaker_cooldown").Int(); err == nil {
+			c.CircuitBreakerCooldown = time.Duration(secs) * time.Second
+		}
+	}
+
+	if sec.HasKey("max_response_size") {
+		if mb, err := sec.Key("max_response_size").Int64(); err == nil {
+			c.MaxResponseSize = mb * 1024 * 1024
+		}
+	}
+
+	if sec.HasKey("response_archive") {
+		c.ResponseArchive = strings.TrimSpace(sec.Key("response_archive").String())
+	}
+
+	if sec.HasKey("maximum_duration") {
+		if mins, err := sec.Key("maximum_duration").Int(); err == nil {
+			c.SourcesTimeout = time.Duration(mins) * time.Minute
+		}
+	}
+
+	if sec.HasKey("source_order") {
+		switch order := strings.ToLower(strings.TrimSpace(sec.Key("source_order").String())); order {
+		case SourceOrderStatic, SourceOrderPriority, SourceOrderYield:
+			c.SourceOrder = order
+		default:
+			return fmt.Errorf("The source_order option must be %s, %s or %s",
+				SourceOrderStatic, SourceOrderPriority, SourceOrderYield)
+		}
+	}
+
+	if sec.HasKey("max_names") {
+		if max, err := sec.Key("max_names").Int(); err == nil {
+			c.MaxSourceNames = max
+		}
+	}
+
+	if sec.HasKey("deduplicate_names") {
+		if dedup, err := sec.Key("deduplicate_names").Bool(); err == nil {
+			c.DeduplicateSourceNames = dedup
+		}
+	}
+
 	for _, child := range sec.ChildSections() {
 		name := strings.Split(child.Name(), ".")[1]
 
 		if name == "disabled" {
 			// Load up all the disabled data source names
-			c.SourceFilter.Sources = stringset.Deduplicate(child.Key("data_source").ValueWithShadows())
+			c.SourceFilter.Sources = deduplicateSourceFilter(child.Key("data_source").ValueWithShadows())
 			c.SourceFilter.Include = false
 			continue
 		}
+		if name == "included" {
+			c.SourceFilter.Included = deduplicateSourceFilter(child.Key("data_source").ValueWithShadows())
+			continue
+		}
+		if name == "excluded" {
+			c.SourceFilter.Excluded = deduplicateSourceFilter(child.Key("data_source").ValueWithShadows())
+			continue
+		}
 
 		dsc := c.GetDataSourceConfig(name)
 		// Parse the Database information and assign to the Config
@@ -113,5 +299,5 @@ func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 		}
 	}
 
-	return nil
+	return c.checkSourceFilter()
 }
@@ -4,7 +4,13 @@
 package config
 
 import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-ini/ini"
 )
@@ -78,20 +84,58 @@ func TestLoadDataSourceSettings(t *testing.T) {
 		[]byte(`
 		[data_sources]
 		minimum_ttl = 1440
+		deduplicate_names = false
+		request_retries = 5
+		retry_delay = 250
+		request_delay = 500
+		request_jitter = 200
+		max_retry_after = 30
+		max_response_size = 50
+		response_archive = /tmp/amass/responses
+		circuit_breaker_threshold = 10
+		circuit_breaker_cooldown = 300
+		max_names = 1000
+		maximum_duration = 30
+		source_order = Yield
 
 		[data_sources.disabled]
 		data_source = CommonCrawl
 
+		[data_sources.excluded]
+		data_source = /^Archive/
+
+		[data_sources.included]
+		data_source = ArchiveIt
+
 		[data_sources.AlienVault]
 		ttl = 4320
+		rate_limit = 0.5
+		max_results = 100000
+		timeout = 45
+		retries = 4
+		priority = 10
+		history = true
+		flex_search = true
+		include_expired = true
+		timestamps = true
+		include_addresses = true
+		max_names = 500
+		url = https://otx.example.com
+		datasets = dns, tls
+		record_types = CNAME, MX
 		[data_sources.AlienVault.Credentials]
 		apikey = fake
 
 		[data_sources.BinaryEdge]
+		retries = 0
 		[data_sources.BinaryEdge.Credentials]
 		apikey = fake2
 		`),
 	)
+	// The names provided again by another data source are dropped unless the setting turns it off
+	if !c.DeduplicateSourceNames {
+		t.Errorf("The data source names are not deduplicated by default")
+	}
 
 	if err := c.loadDataSourceSettings(cfg); err != nil {
 		t.Errorf("Failed to parse the data source settings: %v", err)
@@ -99,13 +143,183 @@ func TestLoadDataSourceSettings(t *testing.T) {
 	if c.MinimumTTL != 1440 {
 		t.Errorf("Failed to load global data source settings")
 	}
+	if len(c.SourceFilter.Excluded) != 1 || len(c.SourceFilter.Included) != 1 {
+		t.Errorf("Failed to load the included and excluded data sources")
+	}
+	if c.RequestRetries != 5 || c.RequestRetryDelay != 250*time.Millisecond || c.MaxRetryAfter != 30*time.Second {
+		t.Errorf("Failed to load the data source request retry settings")
+	}
+	if c.RequestDelay != 500*time.Millisecond || c.RequestJitter != 200*time.Millisecond {
+		t.Errorf("Failed to load the delay between the data source requests")
+	}
+	if c.CircuitBreakerThreshold != 10 || c.CircuitBreakerCooldown != 5*time.Minute {
+		t.Errorf("Failed to load the data source circuit breaker settings")
+	}
+	if c.MaxResponseSize != 50*1024*1024 {
+		t.Errorf("Failed to load the maximum response size")
+	}
+	if c.ResponseArchive != "/tmp/amass/responses" {
+		t.Errorf("Failed to load the response archive directory")
+	}
+	if c.SourcesTimeout != 30*time.Minute {
+		t.Errorf("Failed to load the maximum duration of the data source queries")
+	}
+	if c.SourceOrder != SourceOrderYield {
+		t.Errorf("Failed to load the data source order")
+	}
+	if c.MaxSourceNames != 1000 {
+		t.Errorf("Failed to load the maximum number of names for each data source")
+	}
+	if c.DeduplicateSourceNames {
+		t.Errorf("Failed to load the data source name deduplication setting")
+	}
 
 	dsc := c.GetDataSourceConfig("AlienVault")
 	if dsc != nil {
+		if dsc.RateLimit != 0.5 {
+			t.Errorf("Failed to load the data source rate limit")
+		}
+		if dsc.MaxResults != 100000 {
+			t.Errorf("Failed to load the data source maximum results")
+		}
+		if dsc.Timeout != 45 {
+			t.Errorf("Failed to load the data source request timeout")
+		}
+		if dsc.Retries != 4 {
+			t.Errorf("Failed to load the data source retries")
+		}
+		if dsc.Priority != 10 {
+			t.Errorf("Failed to load the data source priority")
+		}
+		if !dsc.History {
+			t.Errorf("Failed to load the data source history setting")
+		}
+		if !dsc.FlexSearch {
+			t.Errorf("Failed to load the data source flex search setting")
+		}
+		if !dsc.Timestamps {
+			t.Errorf("Failed to load the data source timestamps setting")
+		}
+		if !dsc.IncludeAddrs {
+			t.Errorf("Failed to load the data source include_addresses setting")
+		}
+		if !dsc.IncludeExpired {
+			t.Errorf("Failed to load the data source expired certificates setting")
+		}
+		if dsc.MaxNames != 500 {
+			t.Errorf("Failed to load the data source maximum names")
+		}
+		if dsc.URL != "https://otx.example.com" {
+			t.Errorf("Failed to load the data source URL")
+		}
+		if len(dsc.Datasets) != 2 || dsc.Datasets[1] != "tls" {
+			t.Errorf("Failed to load the data source datasets")
+		}
+		if len(dsc.RecordTypes) != 2 || dsc.RecordTypes[0] != "CNAME" {
+			t.Errorf("Failed to load the data source record types")
+		}
 		if creds := dsc.GetCredentials(); creds == nil || creds.Key != "fake" {
 			t.Errorf("Failed to load data source credentials")
 		}
 	} else {
 		t.Errorf("Failed to load data source settings")
 	}
+	// An explicit zero turns the retries off, while the other data sources keep the global retries
+	if dsc := c.GetDataSourceConfig("BinaryEdge"); dsc == nil || dsc.Retries != 0 {
+		t.Errorf("Failed to load the data source retries of zero")
+	}
+	if dsc := c.GetDataSourceConfig("Crtsh"); dsc == nil || dsc.Retries >= 0 {
+		t.Errorf("The data source without the retries option did not keep the global retries")
+	}
+}
+
+func TestSourceFilterRegexp(t *testing.T) {
+	if re, err := SourceFilterRegexp("CommonCrawl"); re != nil || err != nil {
+		t.Errorf("SourceFilterRegexp returned an expression for a data source name")
+	}
+	if re, err := SourceFilterRegexp("/^cert/"); err != nil || re == nil || !re.MatchString("CertSpotter") {
+		t.Errorf("SourceFilterRegexp failed to return a case-insensitive expression")
+	}
+	if _, err := SourceFilterRegexp("/^Cert(/"); err == nil {
+		t.Errorf("SourceFilterRegexp did not return an error for an invalid expression")
+	}
+
+	c := NewConfig()
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[data_sources]
+
+		[data_sources.excluded]
+		data_source = /^Archive(/
+		data_source = ArchiveIt
+		`),
+	)
+	var warnings bytes.Buffer
+	c.Log = log.New(&warnings, "", 0)
+	// The invalid expression is dropped with a warning, and the other entries are still applied
+	if err := c.loadDataSourceSettings(cfg); err != nil {
+		t.Errorf("Failed to load the data source settings with an invalid filter expression: %v", err)
+	}
+	if !reflect.DeepEqual(c.SourceFilter.Excluded, []string{"archiveit"}) {
+		t.Errorf("The invalid data source filter expression was not dropped: %v", c.SourceFilter.Excluded)
+	}
+	if !strings.Contains(warnings.String(), "/^Archive(/") {
+		t.Errorf("No warning was logged for the invalid data source filter expression")
+	}
+
+	c = NewConfig()
+	c.SourceFilter.Sources = []string{"/[/", "Crtsh"}
+	if err := c.CheckSettings(); err != nil || !reflect.DeepEqual(c.SourceFilter.Sources, []string{"Crtsh"}) {
+		t.Errorf("CheckSettings did not drop the invalid data source filter expression: %v", c.SourceFilter.Sources)
+	}
+}
+
+func TestSourceFilterOnlyInvalidIncluded(t *testing.T) {
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[data_sources]
+
+		[data_sources.included]
+		data_source = /^Archive(/
+		data_source = /[/
+		`),
+	)
+	c := NewConfig()
+	c.Log = log.New(ioutil.Discard, "", 0)
+	// Dropping every entry of the include list would have selected all the data sources
+	if err := c.loadDataSourceSettings(cfg); err == nil {
+		t.Errorf("The include list made only of invalid expressions was accepted")
+	}
+
+	c = NewConfig()
+	c.Log = log.New(ioutil.Discard, "", 0)
+	c.SourceFilter.Include = true
+	c.SourceFilter.Sources = []string{"/^Archive(/", "/[/"}
+	if err := c.CheckSettings(); err == nil {
+		t.Errorf("CheckSettings accepted the include filter made only of invalid expressions")
+	}
+
+	c = NewConfig()
+	c.Log = log.New(ioutil.Discard, "", 0)
+	c.SourceFilter.Sources = []string{"/^Archive(/", "/[/"}
+	if err := c.CheckSettings(); err != nil || len(c.SourceFilter.Sources) != 0 {
+		t.Errorf("CheckSettings did not drop the invalid entries of the exclude filter: %v", err)
+	}
+}
+
+func TestDeduplicateSourceFilter(t *testing.T) {
+	entries := []string{"CommonCrawl", `/^\D/`, "commoncrawl", ` /^\d/`, `/^\D/`, ""}
+
+	expected := []string{"commoncrawl", `/^\D/`, `/^\d/`}
+	if got := deduplicateSourceFilter(entries); !reflect.DeepEqual(got, expected) {
+		t.Errorf("The data source filter entries were deduplicated to %v instead of %v", got, expected)
+	}
 }
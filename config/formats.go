@@ -0,0 +1,158 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-ini/ini"
+)
+
+// The options used for every configuration file format
+var settingsLoadOptions = ini.LoadOptions{
+	Insensitive:  true,
+	AllowShadows: true,
+}
+
+// Loads the configuration file at the provided path. Files with the .json or .toml extension, and
+// files whose content begins with an opening brace, are converted to the structure of the INI format,
+// so the same settings are accepted by every format.
+func loadSettingsFile(path string) (*ini.File, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ini":
+		return ini.LoadSources(settingsLoadOptions, path)
+	case ".json":
+		return loadStructuredSettings(path, json.Unmarshal)
+	case ".toml":
+		return loadStructuredSettings(path, toml.Unmarshal)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return structuredToINI(data, json.Unmarshal)
+	}
+	return ini.LoadSources(settingsLoadOptions, data)
+}
+
+func loadStructuredSettings(path string, unmarshal func([]byte, interface{}) error) (*ini.File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return structuredToINI(data, unmarshal)
+}
+
+// Converts a JSON or TOML document into the INI file structure. Tables become sections named after
+// the path of keys leading to them, such as data_sources.AlienVault.Credentials, and lists become
+// keys with shadow values, such as the data_source keys of the data_sources.disabled section.
+func structuredToINI(data []byte, unmarshal func([]byte, interface{}) error) (*ini.File, error) {
+	var doc map[string]interface{}
+
+	if err := unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	cfg := ini.Empty(settingsLoadOptions)
+	if err := addStructuredSection(cfg, ini.DEFAULT_SECTION, doc); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func addStructuredSection(cfg *ini.File, name string, table map[string]interface{}) error {
+	// The default section already exists, and NewSection would create a lowercase copy of it
+	sec := cfg.Section(ini.DEFAULT_SECTION)
+	if name != ini.DEFAULT_SECTION {
+		var err error
+
+		if sec, err = cfg.NewSection(name); err != nil {
+			return err
+		}
+	}
+
+	// Sorting the keys causes the sections to be created in a predictable order
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch v := table[k].(type) {
+		case map[string]interface{}:
+			child := k
+			if name != ini.DEFAULT_SECTION {
+				child = name + "." + k
+			}
+
+			if err := addStructuredSection(cfg, child, v); err != nil {
+				return err
+			}
+		case []map[string]interface{}:
+			return fmt.Errorf("The %s setting cannot be a list of tables", k)
+		case []interface{}:
+			if err := addStructuredList(sec, k, v); err != nil {
+				return err
+			}
+		default:
+			value, err := structuredValue(v)
+			if err != nil {
+				return fmt.Errorf("The %s setting: %v", k, err)
+			}
+
+			if _, err := sec.NewKey(k, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func addStructuredList(sec *ini.Section, name string, list []interface{}) error {
+	var key *ini.Key
+
+	for _, item := range list {
+		value, err := structuredValue(item)
+		if err != nil {
+			return fmt.Errorf("The %s setting: %v", name, err)
+		}
+
+		if key == nil {
+			key, err = sec.NewKey(name, value)
+		} else {
+			err = key.AddShadow(value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func structuredValue(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("The value type %T is not supported", v)
+}
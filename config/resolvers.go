@@ -25,6 +25,16 @@ const DefaultQueriesPerBaselineResolver = 100
 
 const minResolverReliability = 0.95
 
+// The behaviors available for the names that match a DNS wildcard.
+const (
+	// The names matching a DNS wildcard are suppressed
+	WildcardModeSuppress = "suppress"
+	// The names matching a DNS wildcard are logged, but not suppressed
+	WildcardModeReport = "report"
+	// The DNS wildcard tests are not performed
+	WildcardModeOff = "off"
+)
+
 // DefaultBaselineResolvers is a list of trusted public DNS resolvers.
 var DefaultBaselineResolvers = []string{
 	"8.8.8.8",        // Google
@@ -99,7 +109,31 @@ func (c *Config) loadResolverSettings(cfg *ini.File) error {
 		return nil
 	}
 
+	// The DNS-over-HTTPS server can be configured without replacing the default resolvers
+	if sec.HasKey("doh_server") {
+		c.DoHServer = strings.TrimSpace(sec.Key("doh_server").String())
+		c.DoHFallback = sec.Key("doh_fallback").MustBool(true)
+	}
+
+	if sec.HasKey("wildcard_tests") {
+		if num, err := sec.Key("wildcard_tests").Int(); err == nil && num > 0 {
+			c.WildcardTests = num
+		}
+	}
+
+	if sec.HasKey("wildcard_mode") {
+		switch mode := strings.ToLower(strings.TrimSpace(sec.Key("wildcard_mode").String())); mode {
+		case WildcardModeSuppress, WildcardModeReport, WildcardModeOff:
+			c.WildcardMode = mode
+		default:
+			return fmt.Errorf("The wildcard_mode %s is not supported", mode)
+		}
+	}
+
 	c.Resolvers = stringset.Deduplicate(sec.Key("resolver").ValueWithShadows())
+	if len(c.Resolvers) == 0 && c.DoHServer != "" {
+		return nil
+	}
 	if len(c.Resolvers) == 0 {
 		return errors.New("No resolver keys were found in the resolvers section")
 	}
@@ -4,6 +4,7 @@
 package config
 
 import (
+	"fmt"
 	"net"
 	"regexp"
 	"strings"
@@ -152,6 +153,28 @@ func (c *Config) Blacklisted(name string) bool {
 	return false
 }
 
+// BlockedBy returns the blocklist name or pattern matching the name in the parameter,
+// or an empty string when the name is not blocked.
+func (c *Config) BlockedBy(name string) string {
+	n := strings.ToLower(strings.TrimSpace(name))
+
+	for _, b := range c.BlockedNames {
+		if n == b {
+			return b
+		}
+		if strings.HasPrefix(b, "*.") && strings.HasSuffix(n, b[1:]) {
+			return b
+		}
+	}
+
+	for _, re := range c.BlockedPatterns {
+		if re.MatchString(n) {
+			return re.String()
+		}
+	}
+	return ""
+}
+
 func (c *Config) loadScopeSettings(cfg *ini.File) error {
 	scope, err := cfg.GetSection("scope")
 	if err != nil {
@@ -206,3 +229,31 @@ func (c *Config) loadScopeSettings(cfg *ini.File) error {
 
 	return nil
 }
+
+// Loads the names and patterns that no data source is allowed to provide.
+func (c *Config) loadBlocklistSettings(cfg *ini.File) error {
+	blocklist, err := cfg.GetSection("scope.blocklist")
+	if err != nil {
+		return nil
+	}
+
+	for _, name := range blocklist.Key("name").ValueWithShadows() {
+		if n := strings.ToLower(strings.TrimSpace(name)); n != "" {
+			c.BlockedNames = append(c.BlockedNames, n)
+		}
+	}
+	c.BlockedNames = stringset.Deduplicate(c.BlockedNames)
+
+	for _, pattern := range blocklist.Key("pattern").ValueWithShadows() {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(strings.TrimSpace(pattern))
+		if err != nil {
+			return fmt.Errorf("Failed to compile the blocklist pattern %s: %v", pattern, err)
+		}
+		c.BlockedPatterns = append(c.BlockedPatterns, re)
+	}
+	return nil
+}
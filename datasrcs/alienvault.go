@@ -25,6 +25,7 @@ import (
 // AlienVault is the Service that handles access to the AlienVault data source.
 type AlienVault struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -61,6 +62,9 @@ func (a *AlienVault) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (a *AlienVault) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(a.sys, a, requestStarted(a.sys, a))
+	ctx = withSourceContext(ctx, a.sys, a, 0)
+
 	switch req := args.(type) {
 	case *requests.DNSRequest:
 		a.dnsRequest(ctx, req)
@@ -83,8 +87,25 @@ func (a *AlienVault) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		fmt.Sprintf("Querying %s for %s subdomains", a.String(), req.Domain))
 	a.executeDNSQuery(ctx, req)
 
-	a.CheckRateLimit()
-	a.executeURLQuery(ctx, req)
+	// The URL list is only queried when selected by the datasets option
+	if a.urlListEnabled() {
+		numRateLimitChecks(a, 1)
+		a.executeURLQuery(ctx, req)
+	}
+}
+
+func (a *AlienVault) urlListEnabled() bool {
+	dsc := a.sys.Config().GetDataSourceConfig(a.String())
+	if dsc == nil {
+		return false
+	}
+
+	for _, set := range dsc.Datasets {
+		if strings.ToLower(strings.TrimSpace(set)) == "url_list" {
+			return true
+		}
+	}
+	return false
 }
 
 func (a *AlienVault) whoisRequest(ctx context.Context, req *requests.WhoisRequest) {
@@ -106,38 +127,65 @@ func (a *AlienVault) executeDNSQuery(ctx context.Context, req *requests.DNSReque
 		return
 	}
 
-	u := a.getURL(req.Domain) + "passive_dns"
-	page, err := http.RequestWebPage(ctx, u, nil, a.getHeaders(), nil)
-	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
-		return
-	}
-	// Extract the subdomain names and IP addresses from the passive DNS information
-	var m struct {
-		Subdomains []struct {
-			Hostname string `json:"hostname"`
-			IP       string `json:"address"`
-		} `json:"passive_dns"`
-	}
-	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
-		return
-	} else if len(m.Subdomains) == 0 {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The query returned zero results", a.String(), u))
-		return
-	}
-
 	ips := stringset.New()
 	names := stringset.New()
-	for _, sub := range m.Subdomains {
-		n := strings.ToLower(sub.Hostname)
+	nameRE := pivotNameRegexp(a.sys, re)
+	headers := a.getHeaders()
+	u := a.getURL(req.Domain) + "passive_dns"
+	// Obtain each page of the passive DNS information until the API reports no additional pages
+	for cur := 1; ; cur++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
 
-		if re.MatchString(n) {
-			names.Insert(n)
-			if ip := net.ParseIP(sub.IP); ip != nil {
-				ips.Insert(ip.String())
+		pageURL := u
+		if cur > 1 {
+			numRateLimitChecks(a, 1)
+			pageURL = u + "?page=" + strconv.Itoa(cur)
+		}
+
+		page, err := http.RequestWithRetry(ctx, pageURL, nil, headers, nil)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
+			break
+		}
+		// Extract the subdomain names and IP addresses from the passive DNS information
+		var m struct {
+			HasNext    bool `json:"has_next"`
+			Subdomains []struct {
+				Hostname string `json:"hostname"`
+				IP       string `json:"address"`
+			} `json:"passive_dns"`
+		}
+		if err := json.Unmarshal([]byte(page), &m); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
+			break
+		} else if len(m.Subdomains) == 0 {
+			if cur == 1 {
+				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+					fmt.Sprintf("%s: %s: The query returned zero results", a.String(), pageURL))
 			}
+			break
+		}
+
+		for _, sub := range m.Subdomains {
+			n := strings.ToLower(sub.Hostname)
+
+			if nameRE.MatchString(n) {
+				names.Insert(n)
+			}
+			// Only the addresses of the names within the scope are added to the enumeration
+			if re.MatchString(n) {
+				if ip := net.ParseIP(sub.IP); ip != nil {
+					ips.Insert(ip.String())
+				}
+			}
+		}
+
+		if !m.HasNext {
+			break
 		}
 	}
 
@@ -178,7 +226,7 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 
 	headers := a.getHeaders()
 	u := a.getURL(req.Domain) + "url_list"
-	page, err := http.RequestWebPage(ctx, u, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, u, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return
@@ -202,14 +250,21 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 
 	ips := stringset.New()
 	names := stringset.New()
-	extractNamesIPs(m.URLs, names, ips, re)
+	nameRE := pivotNameRegexp(a.sys, re)
+	extractNamesIPs(m.URLs, names, ips, re, nameRE)
 	// If there are additional pages of URLs, obtain that info as well
 	if m.HasNext {
 		pages := int(math.Ceil(float64(m.FullSize) / float64(m.Limit)))
 		for cur := m.PageNum + 1; cur <= pages; cur++ {
-			a.CheckRateLimit()
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			numRateLimitChecks(a, 1)
 			pageURL := u + "?page=" + strconv.Itoa(cur)
-			page, err = http.RequestWebPage(ctx, pageURL, nil, headers, nil)
+			page, err = http.RequestWithRetry(ctx, pageURL, nil, headers, nil)
 			if err != nil {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 					fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
@@ -227,7 +282,7 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 				break
 			}
 
-			extractNamesIPs(m.URLs, names, ips, re)
+			extractNamesIPs(m.URLs, names, ips, re, nameRE)
 		}
 	}
 
@@ -245,12 +300,15 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 	}
 }
 
-func extractNamesIPs(urls []avURL, names stringset.Set, ips stringset.Set, re *regexp.Regexp) {
+// Inserts the hostnames matching nameRE into names, and the addresses of the hostnames matching re into ips.
+func extractNamesIPs(urls []avURL, names stringset.Set, ips stringset.Set, re, nameRE *regexp.Regexp) {
 	for _, u := range urls {
 		n := strings.ToLower(u.Hostname)
 
-		if re.MatchString(n) {
+		if nameRE.MatchString(n) {
 			names.Insert(n)
+		}
+		if re.MatchString(n) {
 			if ip := net.ParseIP(u.Result.Worker.IP); ip != nil {
 				ips.Insert(ip.String())
 			}
@@ -265,13 +323,13 @@ func (a *AlienVault) executeWhoisQuery(ctx context.Context, req *requests.WhoisR
 	}
 
 	emails := a.queryWhoisForEmails(ctx, req)
-	a.CheckRateLimit()
+	numRateLimitChecks(a, 1)
 
 	newDomains := stringset.New()
 	headers := a.getHeaders()
 	for _, email := range emails {
 		pageURL := a.getReverseWhoisURL(email)
-		page, err := http.RequestWebPage(ctx, pageURL, nil, headers, nil)
+		page, err := http.RequestWithRetry(ctx, pageURL, nil, headers, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 				fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
@@ -292,7 +350,7 @@ func (a *AlienVault) executeWhoisQuery(ctx context.Context, req *requests.WhoisR
 				newDomains.Insert(d.Domain)
 			}
 		}
-		a.CheckRateLimit()
+		numRateLimitChecks(a, 1)
 	}
 
 	if len(newDomains) == 0 {
@@ -319,7 +377,7 @@ func (a *AlienVault) queryWhoisForEmails(ctx context.Context, req *requests.Whoi
 		return emails.Slice()
 	}
 
-	page, err := http.RequestWebPage(ctx, u, nil, a.getHeaders(), nil)
+	page, err := http.RequestWithRetry(ctx, u, nil, a.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return emails.Slice()
@@ -18,6 +18,7 @@ import (
 // Cloudflare is the Service that handles access to the Cloudflare data source.
 type Cloudflare struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -52,8 +53,16 @@ func (c *Cloudflare) OnStart() error {
 	return nil
 }
 
+// RequiredConfigPresent implements the ConfiguredService interface.
+func (c *Cloudflare) RequiredConfigPresent(cfg *config.Config) bool {
+	return apiKeyPresent(cfg, c.String(), false)
+}
+
 // OnRequest implements the Service interface.
 func (c *Cloudflare) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(c.sys, c, requestStarted(c.sys, c))
+	ctx = withSourceContext(ctx, c.sys, c, 0)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		c.dnsRequest(ctx, req)
 	}
@@ -81,35 +90,23 @@ func (c *Cloudflare) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
 	}
 
+	numRateLimitChecks(c, 1)
 	zones, err := api.ListZones(req.Domain)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
 	}
 
 	for _, zone := range zones {
+		numRateLimitChecks(c, 1)
 		records, err := api.DNSRecords(zone.ID, cloudflare.DNSRecord{})
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
 		}
 
 		for _, record := range records {
-			if d := cfg.WhichDomain(record.Name); d != "" {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-					Name:   record.Name,
-					Domain: req.Domain,
-					Tag:    c.SourceType,
-					Source: c.String(),
-				})
-			}
+			genNewNameEvent(ctx, c.sys, c, record.Name)
 			if record.Type == "CNAME" {
-				if d := cfg.WhichDomain(record.Content); d != "" {
-					bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-						Name:   record.Content,
-						Domain: req.Domain,
-						Tag:    c.SourceType,
-						Source: c.String(),
-					})
-				}
+				genNewNameEvent(ctx, c.sys, c, record.Content)
 			}
 		}
 	}
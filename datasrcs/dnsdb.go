@@ -20,9 +20,17 @@ import (
 	"github.com/caffix/stringset"
 )
 
+const (
+	// The number of results requested for each page of the Flexible Search API
+	dnsdbFlexLimit = 10000
+	// The most pages of Flexible Search results requested for a domain
+	dnsdbFlexMaxPages = 10
+)
+
 // DNSDB is the Service that handles access to the DNSDB data source.
 type DNSDB struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -58,9 +66,7 @@ func (d *DNSDB) OnStart() error {
 }
 
 func (d *DNSDB) checkConfig() error {
-	creds := d.sys.Config().GetDataSourceConfig(d.String()).GetCredentials()
-
-	if creds == nil || creds.Key == "" {
+	if !d.RequiredConfigPresent(d.sys.Config()) {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", d.String())
 		d.sys.Config().Log.Print(estr)
 		return errors.New(estr)
@@ -69,8 +75,16 @@ func (d *DNSDB) checkConfig() error {
 	return nil
 }
 
+// RequiredConfigPresent implements the ConfiguredService interface.
+func (d *DNSDB) RequiredConfigPresent(cfg *config.Config) bool {
+	return apiKeyPresent(cfg, d.String(), false)
+}
+
 // OnRequest implements the Service interface.
 func (d *DNSDB) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(d.sys, d, requestStarted(d.sys, d))
+	ctx = withSourceContext(ctx, d.sys, d, 0)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		d.dnsRequest(ctx, req)
 	}
@@ -100,8 +114,13 @@ func (d *DNSDB) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		"Content-Type": "application/json",
 	}
 
+	if dsc := cfg.GetDataSourceConfig(d.String()); dsc != nil && dsc.FlexSearch {
+		d.flexSearch(ctx, req.Domain, headers)
+		return
+	}
+
 	url := d.getURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), url, err))
 		return
@@ -116,6 +135,94 @@ func (d *DNSDB) getURL(domain string) string {
 	return fmt.Sprintf("https://api.dnsdb.info/lookup/rrset/name/*.%s?limit=10000000", domain)
 }
 
+// Queries the Flexible Search API with a glob matching the subdomains of the domain argument.
+// Pages of results are requested until the API no longer reports that the results were limited.
+func (d *DNSDB) flexSearch(ctx context.Context, domain string, headers map[string]string) {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	for i := 0; i < dnsdbFlexMaxPages; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		if i > 0 {
+			numRateLimitChecks(d, 120)
+		}
+
+		url := d.getFlexURL(domain, i*dnsdbFlexLimit)
+		page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), url, err))
+			return
+		}
+
+		names, limited := d.parseFlex(ctx, page, domain)
+		for _, name := range names {
+			genNewNameEvent(ctx, d.sys, d, name)
+		}
+		if !limited || len(names) == 0 {
+			return
+		}
+	}
+}
+
+func (d *DNSDB) getFlexURL(domain string, offset int) string {
+	u := fmt.Sprintf("https://api.dnsdb.info/dnsdb/v2/glob/rrnames/*.%s./ANY?limit=%d", domain, dnsdbFlexLimit)
+	if offset > 0 {
+		u += fmt.Sprintf("&offset=%d", offset)
+	}
+	return u
+}
+
+// Parses the Stream Annotation Format lines returned by the Flexible Search API. The second return
+// value is true when the API reports that more results are available than were returned.
+func (d *DNSDB) parseFlex(ctx context.Context, page, domain string) ([]string, bool) {
+	cfg, _, err := ContextConfigBus(ctx)
+	if err != nil {
+		return []string{}, false
+	}
+
+	re := cfg.DomainRegex(domain)
+	if re == nil {
+		return []string{}, false
+	}
+
+	re = pivotNameRegexp(d.sys, re)
+	var limited bool
+	unique := stringset.New()
+	scanner := bufio.NewScanner(strings.NewReader(page))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		var j struct {
+			Cond string `json:"cond"`
+			Obj  struct {
+				Name string `json:"rrname"`
+			} `json:"obj"`
+		}
+		if err := json.Unmarshal([]byte(line), &j); err != nil {
+			continue
+		}
+		if j.Cond == "limited" {
+			limited = true
+		}
+
+		name := strings.TrimSuffix(j.Obj.Name, ".")
+		if name != "" && re.MatchString(name) {
+			unique.Insert(name)
+		}
+	}
+
+	return unique.Slice(), limited
+}
+
 func (d *DNSDB) parse(ctx context.Context, page, domain string) []string {
 	cfg, _, err := ContextConfigBus(ctx)
 	if err != nil {
@@ -127,6 +234,15 @@ func (d *DNSDB) parse(ctx context.Context, page, domain string) []string {
 		return []string{}
 	}
 
+	// The record data is only searched for the record types selected by the configuration
+	types := stringset.New()
+	if dsc := cfg.GetDataSourceConfig(d.String()); dsc != nil {
+		for _, t := range dsc.RecordTypes {
+			types.Insert(strings.ToUpper(strings.TrimSpace(t)))
+		}
+	}
+
+	re = pivotNameRegexp(d.sys, re)
 	unique := stringset.New()
 	scanner := bufio.NewScanner(strings.NewReader(page))
 	for scanner.Scan() {
@@ -137,7 +253,9 @@ func (d *DNSDB) parse(ctx context.Context, page, domain string) []string {
 		}
 
 		var j struct {
-			Name string `json:"rrname"`
+			Name  string          `json:"rrname"`
+			Type  string          `json:"rrtype"`
+			RData json.RawMessage `json:"rdata"`
 		}
 		err := json.Unmarshal([]byte(line), &j)
 		if err != nil {
@@ -146,6 +264,14 @@ func (d *DNSDB) parse(ctx context.Context, page, domain string) []string {
 		if re.MatchString(j.Name) {
 			unique.Insert(j.Name)
 		}
+		if !types.Has(strings.ToUpper(j.Type)) {
+			continue
+		}
+
+		// The record data can be a string or a list of strings
+		for _, name := range re.FindAllString(string(j.RData), -1) {
+			unique.Insert(name)
+		}
 	}
 
 	return unique.Slice()
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	amasshttp "github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
@@ -19,9 +20,13 @@ import (
 	"github.com/caffix/service"
 )
 
+// The DNSDumpster results page is slow to render for large domains
+const dnsdumpsterTimeout = time.Minute
+
 // DNSDumpster is the Service that handles access to the DNSDumpster data source.
 type DNSDumpster struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -51,6 +56,9 @@ func (d *DNSDumpster) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (d *DNSDumpster) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(d.sys, d, requestStarted(d.sys, d))
+	ctx = withSourceContext(ctx, d.sys, d, dnsdumpsterTimeout)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		d.dnsRequest(ctx, req)
 	}
@@ -71,7 +79,7 @@ func (d *DNSDumpster) dnsRequest(ctx context.Context, req *requests.DNSRequest)
 		fmt.Sprintf("Querying %s for %s subdomains", d.String(), req.Domain))
 
 	u := "https://dnsdumpster.com/"
-	page, err := amasshttp.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := amasshttp.RequestWithRetry(ctx, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), u, err))
 		return
@@ -84,14 +92,14 @@ func (d *DNSDumpster) dnsRequest(ctx context.Context, req *requests.DNSRequest)
 		return
 	}
 
-	d.CheckRateLimit()
+	numRateLimitChecks(d, 1)
 	page, err = d.postForm(ctx, token, req.Domain)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), u, err))
 		return
 	}
 
-	for _, sd := range re.FindAllString(page, -1) {
+	for _, sd := range pivotNameRegexp(d.sys, re).FindAllString(page, -1) {
 		genNewNameEvent(ctx, d.sys, d, amasshttp.CleanName(sd))
 	}
 }
@@ -111,6 +119,9 @@ func (d *DNSDumpster) postForm(ctx context.Context, token, domain string) (strin
 		return "", fmt.Errorf("%s failed to obtain the EventBus from Context", d.String())
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, amasshttp.RequestTimeout(ctx))
+	defer cancel()
+
 	params := url.Values{
 		"csrfmiddlewaretoken": {token},
 		"targetip":            {domain},
@@ -130,7 +141,7 @@ func (d *DNSDumpster) postForm(ctx context.Context, token, domain string) (strin
 	}
 	req.AddCookie(cookie)
 
-	req.Header.Set("User-Agent", amasshttp.UserAgent)
+	req.Header.Set("User-Agent", amasshttp.NextUserAgent())
 	req.Header.Set("Accept", amasshttp.Accept)
 	req.Header.Set("Accept-Language", amasshttp.AcceptLang)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -0,0 +1,172 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+// The longest period of time a data source is given to complete the test query
+const sourceTestTimeout = 2 * time.Minute
+
+// SourceTestResult contains the outcome of querying a data source with TestSources.
+type SourceTestResult struct {
+	Source   string
+	Success  bool
+	Names    int
+	Requests int
+	Errors   int
+	Duration time.Duration
+	Err      error
+}
+
+// testSystem keeps the measurements and names of the data source tests apart from the System
+// being tested, such as the metrics used to order the data sources of later enumerations.
+type testSystem struct {
+	systems.System
+	metrics *systems.Metrics
+}
+
+func newTestSystem(sys systems.System) *testSystem {
+	return &testSystem{
+		System:  sys,
+		metrics: systems.NewMetrics(),
+	}
+}
+
+// Metrics implements the System interface.
+func (t *testSystem) Metrics() *systems.Metrics { return t.metrics }
+
+// Candidates implements the System interface.
+func (t *testSystem) Candidates() *systems.Candidates { return nil }
+
+// SourceGroups implements the System interface.
+func (t *testSystem) SourceGroups() *systems.SourceGroups { return nil }
+
+// Pivots implements the System interface.
+func (t *testSystem) Pivots() *systems.Pivots { return nil }
+
+// NameStream implements the System interface.
+func (t *testSystem) NameStream() *systems.NameStream { return nil }
+
+// TestSources sends a vertical query for the domain argument, such as google.com, to each data
+// source selected by the System configuration and reports how each data source performed. The
+// data sources are built again for the test, so the metrics and names collected by the System
+// are not changed. The data sources are queried concurrently, so this should not be used during
+// an enumeration.
+func TestSources(ctx context.Context, sys systems.System, domain string) []*SourceTestResult {
+	bus := eventbus.NewEventBus()
+	defer bus.Stop()
+
+	// The names found during the test are not sent to another enumeration
+	cfg := config.NewConfig()
+	cfg.AddDomain(domain)
+	cfg.Log = sys.Config().Log
+
+	ctx = context.WithValue(ctx, requests.ContextConfig, cfg)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+
+	var lock sync.Mutex
+	names := make(map[string]int)
+	countNames := func(req *requests.DNSRequest) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		names[req.Source]++
+	}
+	bus.Subscribe(requests.NewNameTopic, countNames)
+	defer bus.Unsubscribe(requests.NewNameTopic, countNames)
+
+	tsys := newTestSystem(sys)
+	var wg sync.WaitGroup
+	var results []*SourceTestResult
+	for _, src := range SelectedDataSources(sys.Config(), GetAllSources(tsys)) {
+		r := &SourceTestResult{Source: src.String()}
+		results = append(results, r)
+
+		wg.Add(1)
+		go func(src service.Service, r *SourceTestResult) {
+			defer wg.Done()
+
+			if err := src.Start(); err != nil {
+				r.Err = err
+				return
+			}
+			defer func() { _ = src.Stop() }()
+
+			testSource(ctx, tsys, src, domain, r)
+		}(src, r)
+	}
+	wg.Wait()
+
+	lock.Lock()
+	for _, r := range results {
+		r.Names = names[r.Source]
+	}
+	lock.Unlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Source < results[j].Source
+	})
+	return results
+}
+
+func testSource(ctx context.Context, sys systems.System, src service.Service, domain string, r *SourceTestResult) {
+	if s, ok := src.(*Script); ok {
+		if err := s.checkConfig(); err != nil {
+			r.Err = err
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sourceTestTimeout)
+	defer cancel()
+
+	// The request is handled directly instead of through the queue of the service, so the test
+	// completes when the data source returns, including when it returns without recording metrics
+	before := sys.Metrics().Get(src.String())
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		src.OnRequest(ctx, &requests.DNSRequest{
+			Name:   domain,
+			Domain: domain,
+			Tag:    requests.DNS,
+			Source: "DNS",
+		})
+	}()
+
+	select {
+	case <-ctx.Done():
+		r.Err = errors.New("The data source did not complete the query in time")
+		return
+	case <-done:
+	}
+
+	after := sys.Metrics().Get(src.String())
+	r.Duration = time.Since(start)
+	r.Requests = after.Requests - before.Requests
+	r.Errors = after.Errors - before.Errors
+	// A missing API key also ends the query without a web request, so the data sources that
+	// make no requests only succeed when they report that their required settings are present
+	r.Success = r.Errors == 0 && (r.Requests > 0 || requiredConfigPresent(sys.Config(), src))
+}
+
+func requiredConfigPresent(cfg *config.Config, src service.Service) bool {
+	c, ok := src.(ConfiguredService)
+
+	return ok && c.RequiredConfigPresent(cfg)
+}
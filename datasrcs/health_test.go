@@ -0,0 +1,116 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/service"
+)
+
+// A data source that makes the number of requests provided, which fail when errs is true.
+// A negative number of requests returns without recording any metrics.
+type healthSource struct {
+	service.BaseService
+	sys        systems.System
+	requests   int
+	errs       bool
+	configured bool
+}
+
+func newHealthSource(sys systems.System, requests int, errs, configured bool) *healthSource {
+	h := &healthSource{
+		sys:        sys,
+		requests:   requests,
+		errs:       errs,
+		configured: configured,
+	}
+
+	h.BaseService = *service.NewBaseService(h, "Health")
+	return h
+}
+
+func (h *healthSource) OnRequest(ctx context.Context, args service.Args) {
+	// The data source returns early without recording any metrics, such as when the domain is out of scope
+	if h.requests < 0 {
+		return
+	}
+	defer recordDuration(h.sys, h, requestStarted(h.sys, h))
+
+	for i := 0; i < h.requests; i++ {
+		var err error
+		if h.errs {
+			err = errors.New("request failed")
+		}
+		recordRequest(h.sys, h, err)
+	}
+}
+
+func (h *healthSource) RequiredConfigPresent(cfg *config.Config) bool {
+	return h.configured
+}
+
+func TestTestSourceSuccess(t *testing.T) {
+	live := newStubSystem(config.NewConfig())
+
+	tests := []struct {
+		Name       string
+		Requests   int
+		Errors     bool
+		Configured bool
+		Success    bool
+	}{
+		{"requests made", 2, false, false, true},
+		{"requests failed", 2, true, true, false},
+		{"no requests or settings", 0, false, false, false},
+		{"no requests with the settings present", 0, false, true, true},
+	}
+
+	for _, test := range tests {
+		tsys := newTestSystem(live)
+		src := newHealthSource(tsys, test.Requests, test.Errors, test.Configured)
+		if err := src.Start(); err != nil {
+			t.Fatalf("%s: Failed to start the data source: %v", test.Name, err)
+		}
+
+		r := &SourceTestResult{Source: src.String()}
+		testSource(context.Background(), tsys, src, "example.com", r)
+		_ = src.Stop()
+
+		if r.Success != test.Success {
+			t.Errorf("%s: the result reported success as %t", test.Name, r.Success)
+		}
+		if r.Requests != test.Requests {
+			t.Errorf("%s: the result reported %d requests instead of %d", test.Name, r.Requests, test.Requests)
+		}
+	}
+
+	// The measurements of the tests are not recorded by the System being tested
+	if sm := live.Metrics().Get("Health"); sm != (systems.SourceMetrics{}) {
+		t.Errorf("The test measurements were recorded in the metrics of the System: %+v", sm)
+	}
+}
+
+func TestTestSourceEarlyReturn(t *testing.T) {
+	tsys := newTestSystem(newStubSystem(config.NewConfig()))
+	src := newHealthSource(tsys, -1, false, true)
+	if err := src.Start(); err != nil {
+		t.Fatalf("Failed to start the data source: %v", err)
+	}
+	defer func() { _ = src.Stop() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r := &SourceTestResult{Source: src.String()}
+	testSource(ctx, tsys, src, "example.com", r)
+	if r.Err != nil || !r.Success {
+		t.Errorf("The data source returning without metrics was not reported as complete: %v", r.Err)
+	}
+}
@@ -18,6 +18,7 @@ import (
 // IPAPI is the Service that handles access to the ipapi data source.
 type IPAPI struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 }
@@ -60,7 +61,8 @@ func (i *IPAPI) addrRequest(ctx context.Context, req *requests.AddrRequest) {
 
 	url := i.restAddrURL(req.Address)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	numRateLimitChecks(i, 1)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", i.String(), url, err))
 		return
@@ -44,6 +44,7 @@ var (
 // NetworksDB is the Service that handles access to the NetworksDB.io data source.
 type NetworksDB struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -84,6 +85,9 @@ func (n *NetworksDB) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (n *NetworksDB) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(n.sys, n, requestStarted(n.sys, n))
+	ctx = withSourceContext(ctx, n.sys, n, 0)
+
 	switch req := args.(type) {
 	case *requests.ASNRequest:
 		n.asnRequest(ctx, req)
@@ -121,7 +125,7 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 	}
 
 	u := n.getIPURL(addr)
-	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -137,7 +141,7 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 
 	numRateLimitChecks(n, 3)
 	u = networksdbBaseURL + matches[1]
-	page, err = http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err = http.RequestWithRetry(ctx, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -181,7 +185,7 @@ func (n *NetworksDB) executeASNQuery(ctx context.Context, asn int, addr string,
 
 	numRateLimitChecks(n, 3)
 	u := n.getASNURL(asn)
-	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -357,7 +361,7 @@ func (n *NetworksDB) apiIPQuery(ctx context.Context, addr string) (string, strin
 	u := n.getAPIIPURL()
 	params := url.Values{"ip": {addr}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := http.RequestWithRetry(ctx, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return "", ""
@@ -405,7 +409,7 @@ func (n *NetworksDB) apiOrgInfoQuery(ctx context.Context, id string) []int {
 	u := n.getAPIOrgInfoURL()
 	params := url.Values{"id": {id}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := http.RequestWithRetry(ctx, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return []int{}
@@ -448,7 +452,7 @@ func (n *NetworksDB) apiASNInfoQuery(ctx context.Context, asn int) *requests.ASN
 	u := n.getAPIASNInfoURL()
 	params := url.Values{"asn": {strconv.Itoa(asn)}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := http.RequestWithRetry(ctx, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return nil
@@ -503,7 +507,7 @@ func (n *NetworksDB) apiNetblocksQuery(ctx context.Context, asn int) stringset.S
 	u := n.getAPINetblocksURL()
 	params := url.Values{"asn": {strconv.Itoa(asn)}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(ctx, u, body, n.getHeaders(), nil)
+	page, err := http.RequestWithRetry(ctx, u, body, n.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return netblocks
@@ -561,7 +565,7 @@ func (n *NetworksDB) whoisRequest(ctx context.Context, req *requests.WhoisReques
 
 	numRateLimitChecks(n, 2)
 	u := n.getDomainToIPURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 		return
@@ -584,7 +588,7 @@ func (n *NetworksDB) whoisRequest(ctx context.Context, req *requests.WhoisReques
 
 		numRateLimitChecks(n, 3)
 		u = networksdbBaseURL + match[1]
-		page, err = http.RequestWebPage(ctx, u, nil, nil, nil)
+		page, err = http.RequestWithRetry(ctx, u, nil, nil, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 			continue
@@ -607,7 +611,7 @@ func (n *NetworksDB) whoisRequest(ctx context.Context, req *requests.WhoisReques
 		first, last := amassnet.FirstLast(cidr)
 		u := n.getDomainsInNetworkURL(first.String(), last.String())
 
-		page, err = http.RequestWebPage(ctx, u, nil, nil, nil)
+		page, err = http.RequestWithRetry(ctx, u, nil, nil, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
 			continue
@@ -18,6 +18,7 @@ import (
 // Pastebin is the Service that handles access to the Pastebin data source.
 type Pastebin struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -47,6 +48,9 @@ func (p *Pastebin) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (p *Pastebin) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(p.sys, p, requestStarted(p.sys, p))
+	ctx = withSourceContext(ctx, p.sys, p, 0)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		p.dnsRequest(ctx, req)
 	}
@@ -63,6 +67,7 @@ func (p *Pastebin) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 
+	re = pivotNameRegexp(p.sys, re)
 	numRateLimitChecks(p, 2)
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 		fmt.Sprintf("Querying %s for %s subdomains", p.String(), req.Domain))
@@ -76,7 +81,7 @@ func (p *Pastebin) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	for _, id := range ids {
 		url := p.webURLDumpData(id)
-		page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+		page, err := http.RequestWithRetry(ctx, url, nil, nil, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", p.String(), url, err))
 			return
@@ -91,7 +96,7 @@ func (p *Pastebin) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 // Extract the IDs from the pastebin Web response.
 func (p *Pastebin) extractIDs(ctx context.Context, domain string) ([]string, error) {
 	url := p.webURLDumpIDs(domain)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, nil, nil)
 	if err != nil {
 		return nil, err
 	}
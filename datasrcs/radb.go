@@ -31,6 +31,7 @@ const (
 // RADb is the Service that handles access to the RADb data source.
 type RADb struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -90,6 +91,9 @@ func (r *RADb) registryRADbURL(registry string) string {
 
 // OnRequest implements the Service interface.
 func (r *RADb) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(r.sys, r, requestStarted(r.sys, r))
+	ctx = withSourceContext(ctx, r.sys, r, 0)
+
 	if req, ok := args.(*requests.ASNRequest); ok {
 		r.asnRequest(ctx, req)
 	}
@@ -100,7 +104,7 @@ func (r *RADb) asnRequest(ctx context.Context, req *requests.ASNRequest) {
 		return
 	}
 
-	r.CheckRateLimit()
+	numRateLimitChecks(r, 1)
 	if req.Address != "" {
 		r.executeASNAddrQuery(ctx, req.Address)
 		return
@@ -117,7 +121,7 @@ func (r *RADb) executeASNAddrQuery(ctx context.Context, addr string) {
 
 	url := r.getIPURL("arin", addr)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return
@@ -178,7 +182,7 @@ func (r *RADb) executeASNQuery(ctx context.Context, asn int, addr, prefix string
 	numRateLimitChecks(r, 2)
 	url := r.getASNURL("arin", strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return
@@ -261,7 +265,7 @@ func (r *RADb) netblocks(ctx context.Context, asn int) stringset.Set {
 	numRateLimitChecks(r, 2)
 	url := r.getNetblocksURL(strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return netblocks
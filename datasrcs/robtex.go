@@ -24,6 +24,7 @@ import (
 // Robtex is the Service that handles access to the Robtex data source.
 type Robtex struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -59,6 +60,9 @@ func (r *Robtex) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (r *Robtex) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(r.sys, r, requestStarted(r.sys, r))
+	ctx = withSourceContext(ctx, r.sys, r, 0)
+
 	switch req := args.(type) {
 	case *requests.DNSRequest:
 		r.dnsRequest(ctx, req)
@@ -97,7 +101,7 @@ func (r *Robtex) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		fmt.Sprintf("Querying %s for %s subdomains", r.String(), req.Domain))
 
 	url := "https://freeapi.robtex.com/pdns/forward/" + req.Domain
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return
@@ -110,7 +114,7 @@ func (r *Robtex) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		} else if line.Type == "NS" || line.Type == "MX" {
 			name := strings.Trim(line.Data, ".")
 
-			if cfg.IsDomainInScope(name) {
+			if cfg.IsDomainInScope(name) || pivotNameRegexp(r.sys, re).MatchString(name) {
 				genNewNameEvent(ctx, r.sys, r, name)
 			}
 		}
@@ -124,7 +128,7 @@ loop:
 		default:
 			numRateLimitChecks(r, 6)
 			url = "https://freeapi.robtex.com/pdns/reverse/" + ip
-			pdns, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+			pdns, err := http.RequestWithRetry(ctx, url, nil, nil, nil)
 			if err != nil {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 					fmt.Sprintf("%s: %s: %v", r.String(), url, err))
@@ -214,7 +218,7 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 
 	numRateLimitChecks(r, 6)
 	url := "https://freeapi.robtex.com/ipquery/" + addr
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return nil
@@ -296,7 +300,7 @@ func (r *Robtex) netblocks(ctx context.Context, asn int) stringset.Set {
 
 	numRateLimitChecks(r, 6)
 	url := "https://freeapi.robtex.com/asquery/" + strconv.Itoa(asn)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return netblocks
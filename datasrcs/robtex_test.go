@@ -0,0 +1,80 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+)
+
+// Returns the transport providing the responses of Robtex for example.com.
+func robtexFixtures() *http.FixtureTransport {
+	ft := http.NewFixtureTransport()
+	ft.Add("https://freeapi.robtex.com/pdns/forward/example.com", &http.Fixture{
+		ContentType: "application/json",
+		Body: `{"rrname":"example.com","rrdata":"ns1.example.com.","rrtype":"NS"}
+{"rrname":"example.com","rrdata":"mail.example.com.","rrtype":"MX"}
+{"rrname":"example.com","rrdata":"mx.other.org.","rrtype":"MX"}
+{"rrname":"example.com","rrdata":"93.184.216.34","rrtype":"A"}`,
+	})
+	ft.Add("https://freeapi.robtex.com/pdns/reverse/93.184.216.34", &http.Fixture{
+		ContentType: "application/json",
+		Body: `{"rrname":"www.example.com.","rrdata":"93.184.216.34","rrtype":"A"}
+this line is not JSON
+{"rrname":"shop.example.com.","rrdata":"93.184.216.34","rrtype":"A"}`,
+	})
+	return ft
+}
+
+func TestRobtexFixtures(t *testing.T) {
+	ft := robtexFixtures()
+	cfg := config.NewConfig()
+	cfg.AddDomain("example.com")
+	// The fixtures are only used for the requests of the System under test
+	cfg.Transport = ft
+	ctx, names, stop := sourceTestContext(cfg)
+	defer stop()
+
+	r := NewRobtex(newStubSystem(cfg))
+	r.OnRequest(ctx, &requests.DNSRequest{
+		Name:   "example.com",
+		Domain: "example.com",
+	})
+
+	expected := []string{"mail.example.com", "ns1.example.com", "shop.example.com", "www.example.com"}
+	if got := names(len(expected)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Robtex provided the names %v instead of %v (requests %v)", got, expected, ft.Requests())
+	}
+	if reqs := ft.Requests(); len(reqs) != 2 {
+		t.Errorf("Robtex sent %d requests instead of 2: %v", len(reqs), reqs)
+	}
+}
+
+func TestRobtexPivots(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("example.com")
+	cfg.Transport = robtexFixtures()
+	ctx, names, stop := sourceTestContext(cfg)
+	defer stop()
+
+	sys := newStubSystem(cfg)
+	sys.pivots = systems.NewPivots(true)
+	r := NewRobtex(sys)
+	r.OnRequest(ctx, &requests.DNSRequest{
+		Name:   "example.com",
+		Domain: "example.com",
+	})
+	names(4)
+
+	// The pivots record the names of the data sources in lowercase
+	expected := map[string][]string{"mx.other.org": {"robtex"}}
+	if got := sys.pivots.Candidates(cfg.Domains()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Robtex provided the pivot candidates %v instead of %v", got, expected)
+	}
+}
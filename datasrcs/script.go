@@ -8,9 +8,12 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -23,15 +26,19 @@ import (
 // Script is the Service that handles access to the Script data source.
 type Script struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
 	luaState   *lua.LState
+	// The code of the script, which is loaded again to check other configurations
+	script string
 	// Script callback functions
 	start      lua.LValue
 	stop       lua.LValue
 	check      lua.LValue
 	vertical   lua.LValue
+	bulk       lua.LValue
 	horizontal lua.LValue
 	address    lua.LValue
 	asn        lua.LValue
@@ -40,6 +47,7 @@ type Script struct {
 	// Regexp to match any subdomain name
 	subre   *regexp.Regexp
 	seconds int
+	timeout time.Duration
 	cancel  context.CancelFunc
 }
 
@@ -54,6 +62,7 @@ func NewScript(script string, sys systems.System) *Script {
 
 	s := &Script{
 		sys:    sys,
+		script: script,
 		subre:  re,
 		cancel: cancel,
 	}
@@ -89,6 +98,7 @@ func NewScript(script string, sys systems.System) *Script {
 		return nil
 	}
 	s.BaseService = *service.NewBaseService(s, name)
+	s.timeout = s.scriptTimeout()
 
 	// Save references to the callbacks defined within the script
 	s.getScriptCallbacks()
@@ -108,6 +118,7 @@ func (s *Script) newLuaState(cfg *config.Config) *lua.LState {
 	L.SetGlobal("log", L.NewFunction(s.log))
 	L.SetGlobal("find", L.NewFunction(s.find))
 	L.SetGlobal("submatch", L.NewFunction(s.submatch))
+	L.SetGlobal("base64encode", L.NewFunction(s.base64Encode))
 	L.SetGlobal("newname", L.NewFunction(s.newName))
 	L.SetGlobal("newaddr", L.NewFunction(s.newAddr))
 	L.SetGlobal("newasn", L.NewFunction(s.newASN))
@@ -116,12 +127,15 @@ func (s *Script) newLuaState(cfg *config.Config) *lua.LState {
 	L.SetGlobal("request", L.NewFunction(s.request))
 	L.SetGlobal("scrape", L.NewFunction(s.scrape))
 	L.SetGlobal("crawl", L.NewFunction(s.crawl))
+	L.SetGlobal("resolve", L.NewFunction(s.resolve))
 	L.SetGlobal("outputdir", L.NewFunction(s.outputdir))
 	L.SetGlobal("setratelimit", L.NewFunction(s.setRateLimit))
 	L.SetGlobal("checkratelimit", L.NewFunction(s.checkRateLimit))
+	L.SetGlobal("sleep", L.NewFunction(s.sleep))
 	L.SetGlobal("obtain_response", L.NewFunction(s.obtainResponse))
 	L.SetGlobal("cache_response", L.NewFunction(s.cacheResponse))
 	L.SetGlobal("subdomainre", lua.LString(dns.AnySubdomainRegexString()))
+	L.SetGlobal("wildcardre", lua.LString(`(?:\*\.)?`+dns.AnySubdomainRegexString()))
 	return L
 }
 
@@ -133,6 +147,7 @@ func (s *Script) getScriptCallbacks() {
 	s.stop = L.GetGlobal("stop")
 	s.check = L.GetGlobal("check")
 	s.vertical = L.GetGlobal("vertical")
+	s.bulk = L.GetGlobal("bulk")
 	s.horizontal = L.GetGlobal("horizontal")
 	s.address = L.GetGlobal("address")
 	s.asn = L.GetGlobal("asn")
@@ -172,6 +187,14 @@ func (s *Script) scriptType() (string, error) {
 	return "", errors.New("The script global 'type' is not a string")
 }
 
+// Acquires the HTTP request timeout, in seconds, from the optional 'timeout' global of the script.
+func (s *Script) scriptTimeout() time.Duration {
+	if n, ok := s.luaState.GetGlobal("timeout").(lua.LNumber); ok && n > 0 {
+		return time.Duration(float64(n) * float64(time.Second))
+	}
+	return 0
+}
+
 // Description implements the Service interface.
 func (s *Script) Description() string {
 	return s.SourceType
@@ -226,42 +249,100 @@ func (s *Script) OnStop() error {
 }
 
 func (s *Script) checkConfig() error {
-	L := s.luaState
+	passed, err := s.callCheck()
+	if err != nil {
+		estr := fmt.Sprintf("%s: check callback: %v", s.String(), err)
 
-	if s.check.Type() == lua.LTNil {
+		s.sys.Config().Log.Print(estr)
+		return errors.New(estr)
+	}
+	if passed {
 		return nil
 	}
 
+	estr := fmt.Sprintf("%s: check callback failed for the configuration", s.String())
+	s.sys.Config().Log.Print(estr)
+	return errors.New(estr)
+}
+
+// RequiredConfigPresent implements the ConfiguredService interface using the check callback.
+// The callback of a configuration other than the one of the System is called within a separate
+// Lua state, since the datasrc_config function of the script returns the settings of the System.
+func (s *Script) RequiredConfigPresent(cfg *config.Config) bool {
+	if cfg != s.sys.Config() {
+		_, passed := checkScriptConfig(cfg, s.script)
+		return passed
+	}
+
+	passed, err := s.callCheck()
+	return err == nil && passed
+}
+
+// Returns whether the check callback accepted the configuration, which is true without the callback.
+func (s *Script) callCheck() (bool, error) {
+	return callCheckFunc(s.luaState, s.check)
+}
+
+func callCheckFunc(L *lua.LState, check lua.LValue) (bool, error) {
+	if check.Type() == lua.LTNil {
+		return true, nil
+	}
+
 	err := L.CallByParam(lua.P{
-		Fn:      s.check,
+		Fn:      check,
 		NRet:    1,
 		Protect: true,
 	})
 	if err != nil {
-		estr := fmt.Sprintf("%s: check callback: %v", s.String(), err)
-
-		s.sys.Config().Log.Print(estr)
-		return errors.New(estr)
+		return false, err
 	}
 
 	ret := L.Get(-1)
 	L.Pop(1)
 
 	passed, ok := ret.(lua.LBool)
-	if ok && bool(passed) {
-		return nil
+	return ok && bool(passed), nil
+}
+
+// Returns the name of the script and whether its check callback accepts the configuration. The script
+// is loaded into a Lua state that only provides the datasrc_config function, so the data source is not
+// constructed. The name is empty when the script cannot be loaded.
+func checkScriptConfig(cfg *config.Config, script string) (string, bool) {
+	L := lua.NewState()
+	defer L.Close()
+
+	var name string
+	L.PreloadModule("url", luaurl.Loader)
+	L.PreloadModule("json", luajson.Loader)
+	L.SetGlobal("datasrc_config", L.NewFunction(func(L *lua.LState) int {
+		L.Push(dataSourceConfigTable(L, cfg.GetDataSourceConfig(name)))
+		return 1
+	}))
+
+	if err := L.DoString(script); err != nil {
+		return "", false
+	}
+	if str, ok := L.GetGlobal("name").(lua.LString); ok {
+		name = string(str)
+	}
+	if name == "" {
+		return "", false
 	}
 
-	estr := fmt.Sprintf("%s: check callback failed for the configuration", s.String())
-	s.sys.Config().Log.Print(estr)
-	return errors.New(estr)
+	passed, err := callCheckFunc(L, L.GetGlobal("check"))
+	return name, err == nil && passed
 }
 
 // OnRequest implements the Service interface.
 func (s *Script) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(s.sys, s, requestStarted(s.sys, s))
+	ctx = withSourceContext(ctx, s.sys, s, s.timeout)
+
 	switch req := args.(type) {
 	case *requests.DNSRequest:
-		s.dnsRequest(ctx, req)
+		s.dnsRequest(http.WithRequestDomain(ctx, req.Domain), req)
+	case *requests.BulkDNSRequest:
+		s.bulkRequest(ctx, req)
 	case *requests.ResolvedRequest:
 		s.resolvedRequest(ctx, req)
 	case *requests.SubdomainRequest:
@@ -303,6 +384,44 @@ func (s *Script) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 }
 
+// SupportsBulk implements the systems.BulkService interface.
+func (s *Script) SupportsBulk() bool {
+	return s.bulk.Type() != lua.LTNil
+}
+
+func (s *Script) bulkRequest(ctx context.Context, req *requests.BulkDNSRequest) {
+	L := s.luaState
+
+	if s.bulk.Type() == lua.LTNil || req == nil || len(req.Domains) == 0 {
+		return
+	}
+
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	domains := L.NewTable()
+	for _, domain := range req.Domains {
+		domains.Append(lua.LString(domain))
+	}
+
+	numRateLimitChecks(s, s.seconds)
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", s.String(), strings.Join(req.Domains, ", ")))
+
+	err = L.CallByParam(lua.P{
+		Fn:      s.bulk,
+		NRet:    0,
+		Protect: true,
+	}, s.contextToUserData(ctx), domains)
+
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("%s: bulk callback: %v", s.String(), err))
+	}
+}
+
 func (s *Script) resolvedRequest(ctx context.Context, req *requests.ResolvedRequest) {
 	L := s.luaState
 
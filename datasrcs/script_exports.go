@@ -5,6 +5,7 @@ package datasrcs
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net"
@@ -16,9 +17,11 @@ import (
 	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/OWASP/Amass/v3/stringfilter"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -116,6 +119,7 @@ func (s *Script) config(L *lua.LState) int {
 	tb.RawSetString("add_words", lua.LBool(cfg.AddWords))
 	tb.RawSetString("add_numbers", lua.LBool(cfg.AddNumbers))
 	tb.RawSetString("edit_distance", lua.LNumber(cfg.EditDistance))
+	tb.RawSetString("max_per_name", lua.LNumber(cfg.MaxAltsPerName))
 	r.RawSetString("alterations", tb)
 
 	L.Push(r)
@@ -123,10 +127,14 @@ func (s *Script) config(L *lua.LState) int {
 }
 
 func (s *Script) dataSourceConfig(L *lua.LState) int {
-	cfg := s.sys.Config().GetDataSourceConfig(s.String())
+	L.Push(dataSourceConfigTable(L, s.sys.Config().GetDataSourceConfig(s.String())))
+	return 1
+}
+
+// Returns the table provided to scripts by the datasrc_config function, or nil without a configuration.
+func dataSourceConfigTable(L *lua.LState, cfg *config.DataSourceConfig) lua.LValue {
 	if cfg == nil {
-		L.Push(lua.LNil)
-		return 1
+		return lua.LNil
 	}
 
 	tb := L.NewTable()
@@ -134,6 +142,43 @@ func (s *Script) dataSourceConfig(L *lua.LState) int {
 	if cfg.TTL != 0 {
 		tb.RawSetString("ttl", lua.LNumber(cfg.TTL))
 	}
+	if cfg.MaxResults > 0 {
+		tb.RawSetString("max_results", lua.LNumber(cfg.MaxResults))
+	}
+	if cfg.History {
+		tb.RawSetString("history", lua.LTrue)
+	}
+	if cfg.Timestamps {
+		tb.RawSetString("timestamps", lua.LTrue)
+	}
+	if cfg.IncludeAddrs {
+		tb.RawSetString("include_addresses", lua.LTrue)
+	}
+	if cfg.IncludeExpired {
+		tb.RawSetString("include_expired", lua.LTrue)
+	}
+	if cfg.KeepWildcards {
+		tb.RawSetString("keep_wildcards", lua.LTrue)
+	}
+	if cfg.URL != "" {
+		tb.RawSetString("url", lua.LString(cfg.URL))
+	}
+	if len(cfg.Datasets) > 0 {
+		sets := L.NewTable()
+
+		for _, set := range cfg.Datasets {
+			sets.Append(lua.LString(strings.ToLower(strings.TrimSpace(set))))
+		}
+		tb.RawSetString("datasets", sets)
+	}
+	if len(cfg.RecordTypes) > 0 {
+		types := L.NewTable()
+
+		for _, t := range cfg.RecordTypes {
+			types.Append(lua.LString(strings.ToUpper(strings.TrimSpace(t))))
+		}
+		tb.RawSetString("record_types", types)
+	}
 
 	if creds := cfg.GetCredentials(); creds != nil {
 		c := L.NewTable()
@@ -154,8 +199,7 @@ func (s *Script) dataSourceConfig(L *lua.LState) int {
 		tb.RawSetString("credentials", c)
 	}
 
-	L.Push(tb)
-	return 1
+	return tb
 }
 
 // Wrapper so that scripts can obtain the brute force wordlist for the current enumeration.
@@ -213,6 +257,29 @@ func (s *Script) checkRateLimit(L *lua.LState) int {
 	return 0
 }
 
+// Wrapper so scripts can wait between requests, such as while polling an asynchronous search.
+// Returns false when the context was cancelled before the number of seconds elapsed.
+func (s *Script) sleep(L *lua.LState) int {
+	c := L.CheckUserData(1).Value.(*contextWrapper)
+
+	num, ok := L.Get(2).(lua.LNumber)
+	if !ok || num <= 0 {
+		L.Push(lua.LTrue)
+		return 1
+	}
+
+	t := time.NewTimer(time.Duration(float64(num) * float64(time.Second)))
+	defer t.Stop()
+
+	select {
+	case <-c.Ctx.Done():
+		L.Push(lua.LFalse)
+	case <-t.C:
+		L.Push(lua.LTrue)
+	}
+	return 1
+}
+
 // Wrapper so that scripts can request the path to the Amass output directory.
 func (s *Script) outputdir(L *lua.LState) int {
 	var dir string
@@ -260,10 +327,69 @@ func (s *Script) newName(L *lua.LState) int {
 		return 0
 	}
 
-	genNewNameEvent(c.Ctx, s.sys, s, http.CleanName(name))
+	// The optional third argument provides the addresses of the name, as a string or table of strings
+	var addrs []string
+	switch v := L.Get(3).(type) {
+	case lua.LString:
+		addrs = append(addrs, string(v))
+	case *lua.LTable:
+		v.ForEach(func(_, value lua.LValue) {
+			if a, ok := value.(lua.LString); ok {
+				addrs = append(addrs, string(a))
+			}
+		})
+	}
+
+	// Certificate names beginning with a wildcard label are kept as distinct entries when configured
+	if strings.HasPrefix(strings.TrimSpace(string(n)), "*.") && s.keepWildcards() {
+		name = http.CleanWildcardName("*."+name, true)
+	} else {
+		name = http.CleanName(name)
+	}
+	req := &requests.DNSRequest{
+		Name:    name,
+		Records: addrRecords(name, addrs),
+	}
+	// The optional fourth argument provides the first_seen and last_seen dates of the name,
+	// and whether the addresses are the current resolution of the name
+	if tb, ok := L.Get(4).(*lua.LTable); ok {
+		req.FirstSeen = parseSeenDate(tb.RawGetString("first_seen"))
+		req.LastSeen = parseSeenDate(tb.RawGetString("last_seen"))
+		req.CurrentAddrs = lua.LVAsBool(tb.RawGetString("current"))
+	}
+
+	genNewNameRequest(c.Ctx, s.sys, s, req)
 	return 0
 }
 
+func (s *Script) keepWildcards() bool {
+	dsc := s.sys.Config().GetDataSourceConfig(s.String())
+
+	return dsc != nil && dsc.KeepWildcards
+}
+
+// The date layouts accepted for the first_seen and last_seen fields of the newname table.
+var seenDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func parseSeenDate(lv lua.LValue) time.Time {
+	switch v := lv.(type) {
+	case lua.LNumber:
+		// Numbers are interpreted as seconds since the Unix epoch
+		return time.Unix(int64(v), 0).UTC()
+	case lua.LString:
+		for _, layout := range seenDateLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(string(v))); err == nil {
+				return t.UTC()
+			}
+		}
+	}
+	return time.Time{}
+}
+
 // Wrapper so that scripts can send discovered IP addresses to Amass.
 func (s *Script) newAddr(L *lua.LState) int {
 	c := L.CheckUserData(1).Value.(*contextWrapper)
@@ -465,6 +591,20 @@ func (s *Script) submatch(L *lua.LState) int {
 	return 1
 }
 
+// Wrapper that exposes the padded base64 encoding, which uses the URL-safe alphabet when the
+// optional second argument is true.
+func (s *Script) base64Encode(L *lua.LState) int {
+	data := L.CheckString(1)
+
+	enc := base64.StdEncoding
+	if L.OptBool(2, false) {
+		enc = base64.URLEncoding
+	}
+
+	L.Push(lua.LString(enc.EncodeToString([]byte(data))))
+	return 1
+}
+
 // Converts Go Content to Lua UserData.
 func (s *Script) contextToUserData(ctx context.Context) *lua.LUserData {
 	L := s.luaState
@@ -531,7 +671,7 @@ func (s *Script) request(L *lua.LState) int {
 	id, _ := getStringField(L, opt, "id")
 	pass, _ := getStringField(L, opt, "pass")
 
-	page, err := http.RequestWebPage(c.Ctx, url, body, headers,
+	page, err := http.RequestWithRetry(c.Ctx, url, body, headers,
 		&http.BasicAuth{
 			Username: id,
 			Password: pass,
@@ -550,6 +690,57 @@ func (s *Script) request(L *lua.LState) int {
 	return 2
 }
 
+// Wrapper so that scripts can resolve DNS names, using the DNS-over-HTTPS server when it's configured.
+func (s *Script) resolve(L *lua.LState) int {
+	c := L.CheckUserData(1).Value.(*contextWrapper)
+	name := L.CheckString(2)
+
+	qtype := dns.TypeA
+	if t := L.OptString(3, ""); t != "" {
+		var found bool
+
+		if qtype, found = dns.StringToType[strings.ToUpper(t)]; !found {
+			L.Push(lua.LNil)
+			L.Push(lua.LString("Unknown DNS record type: " + t))
+			return 2
+		}
+	}
+
+	var err error
+	var resp *dns.Msg
+	if doh := s.sys.DoH(); doh != nil {
+		resp, err = doh.Resolve(c.Ctx, name, qtype)
+	} else {
+		resp, err = s.sys.Pool().Query(c.Ctx, resolvers.QueryMsg(name, qtype),
+			resolvers.PriorityHigh, resolvers.PoolRetryPolicy)
+	}
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("The query returned " + dns.RcodeToString[resp.Rcode]))
+		return 2
+	}
+
+	// The records are provided in the same form as the resolved callback
+	records := L.NewTable()
+	for _, a := range resolvers.ExtractAnswers(resp) {
+		tb := L.NewTable()
+
+		tb.RawSetString("rrname", lua.LString(a.Name))
+		tb.RawSetString("rrtype", lua.LNumber(a.Type))
+		tb.RawSetString("rrdata", lua.LString(a.Data))
+		records.Append(tb)
+	}
+
+	L.Push(records)
+	L.Push(lua.LNil)
+	return 2
+}
+
 // Wrapper so that scripts can scrape the contents of a GET request for subdomain names in scope.
 func (s *Script) scrape(L *lua.LState) int {
 	c := L.CheckUserData(1).Value.(*contextWrapper)
@@ -587,7 +778,7 @@ func (s *Script) scrape(L *lua.LState) int {
 	}
 
 	if resp == "" {
-		resp, err = http.RequestWebPage(c.Ctx, url, nil, headers,
+		resp, err = http.RequestWithRetry(c.Ctx, url, nil, headers,
 			&http.BasicAuth{
 				Username: id,
 				Password: pass,
@@ -642,7 +833,22 @@ func (s *Script) crawl(L *lua.LState) int {
 		return 0
 	}
 
-	names, err := http.Crawl(c.Ctx, string(u), cfg.Domains(), int(max), nil)
+	opts := http.NewCrawlOptions(time.Duration(cfg.CrawlTimeout)*time.Second,
+		time.Duration(cfg.CrawlRequestDelay)*time.Millisecond,
+		cfg.CrawlConcurrentRequests, cfg.CrawlMaxRequests, cfg.HonorRobotsTxt)
+	opts.MaxLinks = int(max)
+	if depth, ok := L.Get(4).(lua.LNumber); ok {
+		opts.MaxDepth = int(depth)
+	}
+
+	crawls := s.sys.CrawlLimiter()
+	if !crawls.Acquire(c.Ctx) {
+		return 0
+	}
+
+	names, err := http.CrawlWithOptions(c.Ctx, string(u), cfg.Domains(), nil, opts)
+	crawls.Release()
+	recordRequest(s.sys, s, err)
 	if err != nil {
 		if cfg.Verbose {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), u, err))
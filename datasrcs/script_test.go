@@ -0,0 +1,62 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+func TestScriptRequiredConfigPresent(t *testing.T) {
+	script := `
+name = "Keyed"
+type = "api"
+
+function check()
+    local cfg = datasrc_config()
+    return (cfg ~= nil and cfg.credentials ~= nil and cfg.credentials.key ~= nil and cfg.credentials.key ~= "")
+end
+`
+	syscfg := config.NewConfig()
+	s := NewScript(script, newStubSystem(syscfg))
+	if s == nil {
+		t.Fatal("NewScript failed to load the script")
+	}
+	defer s.luaState.Close()
+
+	keyed := config.NewConfig()
+	if err := keyed.GetDataSourceConfig("Keyed").AddCredentials(&config.Credentials{Name: "account", Key: "key"}); err != nil {
+		t.Fatalf("AddCredentials returned an error: %v", err)
+	}
+
+	if s.RequiredConfigPresent(syscfg) {
+		t.Errorf("The check accepted the configuration of the System without the API key")
+	}
+	if !s.RequiredConfigPresent(keyed) {
+		t.Errorf("The check did not use the configuration provided with the API key")
+	}
+}
+
+func TestScriptBase64Encode(t *testing.T) {
+	script := `
+name = "Encoder"
+type = "api"
+
+std = base64encode("domain=\"owasp.org?\"")
+urlsafe = base64encode("domain=\"owasp.org?\"", true)
+`
+	s := NewScript(script, newStubSystem(config.NewConfig()))
+	if s == nil {
+		t.Fatal("NewScript failed to load the script")
+	}
+	defer s.luaState.Close()
+
+	if got := s.luaState.GetGlobal("std").String(); got != "ZG9tYWluPSJvd2FzcC5vcmc/Ig==" {
+		t.Errorf("The standard encoding returned %s", got)
+	}
+	if got := s.luaState.GetGlobal("urlsafe").String(); got != "ZG9tYWluPSJvd2FzcC5vcmc_Ig==" {
+		t.Errorf("The URL-safe encoding returned %s", got)
+	}
+}
@@ -31,6 +31,7 @@ const (
 // ShadowServer is the Service that handles access to the ShadowServer data source.
 type ShadowServer struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -72,6 +73,9 @@ func (s *ShadowServer) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (s *ShadowServer) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(s.sys, s, requestStarted(s.sys, s))
+	ctx = withSourceContext(ctx, s.sys, s, 0)
+
 	if req, ok := args.(*requests.ASNRequest); ok {
 		s.asnRequest(ctx, req)
 	}
@@ -101,7 +105,7 @@ func (s *ShadowServer) executeASNQuery(ctx context.Context, asn int) {
 		return
 	}
 
-	s.CheckRateLimit()
+	numRateLimitChecks(s, 1)
 	req := s.origin(ctx, strings.Trim(blocks.Slice()[0], "/"))
 	if req == nil {
 		return
@@ -122,7 +126,7 @@ func (s *ShadowServer) executeASNAddrQuery(ctx context.Context, addr string) {
 		return
 	}
 
-	s.CheckRateLimit()
+	numRateLimitChecks(s, 1)
 	req.Netblocks.Union(s.netblocks(ctx, req.ASN))
 	bus.Publish(requests.NewASNTopic, eventbus.PriorityHigh, req)
 }
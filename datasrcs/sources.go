@@ -6,9 +6,19 @@ package datasrcs
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"runtime"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/OWASP/Amass/v3/config"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -16,30 +26,94 @@ import (
 	"github.com/caffix/stringset"
 )
 
-// GetAllSources returns a slice of all data source services, initialized and ready.
+// Matches the DNS names that can be collected as pivot candidates when they are outside the scope.
+var anyNameRE = amassdns.AnySubdomainRegex()
+
+// Returns the regular expression that finds the names a data source provides within its results, where re
+// matches the names within the scope. The names outside the scope are also found when the System collects
+// them as pivot candidates, since genNewNameRequest applies the scope before sending the names.
+func pivotNameRegexp(sys systems.System, re *regexp.Regexp) *regexp.Regexp {
+	if sys != nil && sys.Pivots() != nil {
+		return anyNameRE
+	}
+	return re
+}
+
+// GetAllSources returns a slice of all data source services, initialized and ready. The data sources
+// implementing ConfiguredService are excluded when the configuration lacks the settings they require.
 func GetAllSources(sys systems.System) []service.Service {
-	srvs := []service.Service{
-		NewAlienVault(sys),
-		NewCloudflare(sys),
-		NewDNSDB(sys),
-		NewDNSDumpster(sys),
-		NewNetworksDB(sys),
-		NewPastebin(sys),
-		NewRADb(sys),
-		NewRobtex(sys),
-		NewShadowServer(sys),
-		NewTeamCymru(sys),
-		NewTwitter(sys),
-		NewUmbrella(sys),
-		NewURLScan(sys),
-		NewWhoisXML(sys),
+	return excludeUnconfigured(sys.Config(), AllSources(sys))
+}
+
+// The data sources implemented in Go. The check of the settings required by a data source, when it
+// has one, allows the enabled data sources to be determined without constructing them.
+var goSources = []struct {
+	name  string
+	new   func(sys systems.System) service.Service
+	check func(cfg *config.Config) bool
+}{
+	{"AlienVault", func(sys systems.System) service.Service { return NewAlienVault(sys) }, nil},
+	{"Cloudflare", func(sys systems.System) service.Service { return NewCloudflare(sys) }, apiKeyCheck("Cloudflare", false)},
+	{"DNSDB", func(sys systems.System) service.Service { return NewDNSDB(sys) }, apiKeyCheck("DNSDB", false)},
+	{"DNSDumpster", func(sys systems.System) service.Service { return NewDNSDumpster(sys) }, nil},
+	{"NetworksDB", func(sys systems.System) service.Service { return NewNetworksDB(sys) }, nil},
+	{"Pastebin", func(sys systems.System) service.Service { return NewPastebin(sys) }, nil},
+	{"RADb", func(sys systems.System) service.Service { return NewRADb(sys) }, nil},
+	{"Robtex", func(sys systems.System) service.Service { return NewRobtex(sys) }, nil},
+	{"ShadowServer", func(sys systems.System) service.Service { return NewShadowServer(sys) }, nil},
+	{"TeamCymru", func(sys systems.System) service.Service { return NewTeamCymru(sys) }, nil},
+	{"Twitter", func(sys systems.System) service.Service { return NewTwitter(sys) }, apiKeyCheck("Twitter", true)},
+	{"Umbrella", func(sys systems.System) service.Service { return NewUmbrella(sys) }, apiKeyCheck("Umbrella", false)},
+	{"URLScan", func(sys systems.System) service.Service { return NewURLScan(sys) }, nil},
+	{"WhoisXML", func(sys systems.System) service.Service { return NewWhoisXML(sys) }, apiKeyCheck("WhoisXML", false)},
+}
+
+// Returns true when the configuration provides an API key for the named data source,
+// along with the secret when it's required.
+func apiKeyPresent(cfg *config.Config, name string, secret bool) bool {
+	creds := cfg.GetDataSourceConfig(name).GetCredentials()
+
+	return creds != nil && creds.Key != "" && (!secret || creds.Secret != "")
+}
+
+func apiKeyCheck(name string, secret bool) func(cfg *config.Config) bool {
+	return func(cfg *config.Config) bool {
+		return apiKeyPresent(cfg, name, secret)
+	}
+}
+
+// AllSources returns a slice of all data source services, including those missing the configuration
+// they require, such as for listing the data sources that are not available.
+func AllSources(sys systems.System) []service.Service {
+	var constructors []func() service.Service
+	for _, src := range goSources {
+		src := src
+
+		constructors = append(constructors, func() service.Service { return src.new(sys) })
 	}
 
 	if scripts, err := sys.Config().AcquireScripts(); err == nil {
 		for _, script := range scripts {
-			if s := NewScript(script, sys); s != nil {
-				srvs = append(srvs, s)
-			}
+			script := script
+
+			constructors = append(constructors, func() service.Service {
+				// Avoid returning a non-nil interface holding a nil pointer
+				if s := NewScript(script, sys); s != nil {
+					return s
+				}
+				return nil
+			})
+		}
+	}
+
+	srvs := buildSources(constructors, runtime.NumCPU())
+	for _, src := range srvs {
+		r, ok := src.(configuredRateLimiter)
+		if !ok {
+			continue
+		}
+		if dsc := sys.Config().GetDataSourceConfig(src.String()); dsc != nil {
+			r.setConfiguredRateLimit(dsc.RateLimit)
 		}
 	}
 
@@ -49,22 +123,120 @@ func GetAllSources(sys systems.System) []service.Service {
 	return srvs
 }
 
-// SelectedDataSources uses the config and available data sources to return the selected data sources.
-func SelectedDataSources(cfg *config.Config, avail []service.Service) []service.Service {
-	specified := stringset.New()
-	specified.InsertMany(cfg.SourceFilter.Sources...)
+// ConfiguredService is implemented by data sources that cannot provide results without settings,
+// such as an API key, that must be present in the configuration.
+type ConfiguredService interface {
+	service.Service
+
+	// RequiredConfigPresent returns true when the configuration provides the settings the data source requires
+	RequiredConfigPresent(cfg *config.Config) bool
+}
+
+// Removes the data sources missing their required configuration, which are reported with a single log line.
+func excludeUnconfigured(cfg *config.Config, srvs []service.Service) []service.Service {
+	var missing []string
+	var results []service.Service
+	for _, src := range srvs {
+		if c, ok := src.(ConfiguredService); ok && !c.RequiredConfigPresent(cfg) {
+			missing = append(missing, src.String())
+			// Release the Lua state, since the script will never be started
+			if s, ok := src.(*Script); ok {
+				s.cancel()
+				s.luaState.Close()
+			}
+			continue
+		}
+		results = append(results, src)
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		cfg.Log.Printf("Excluded the data sources missing their required configuration: %s", strings.Join(missing, ", "))
+	}
+	return results
+}
+
+// ListEnabledSources returns the names of the data sources selected by the configuration that have the
+// settings they require. Sources with an API key in the configuration are annotated. The data sources
+// are not constructed, so no network clients are created. The scripts are evaluated by their check callbacks.
+func ListEnabledSources(sys systems.System) []string {
+	cfg := sys.Config()
 
 	available := stringset.New()
-	for _, src := range avail {
-		available.Insert(src.String())
+	for _, src := range goSources {
+		if src.check == nil || src.check(cfg) {
+			available.Insert(src.name)
+		}
+	}
+	if scripts, err := cfg.AcquireScripts(); err == nil {
+		for _, script := range scripts {
+			if name, passed := checkScriptConfig(cfg, script); passed {
+				available.Insert(name)
+			}
+		}
 	}
 
-	if specified.Len() > 0 && cfg.SourceFilter.Include {
-		available.Intersect(specified)
-	} else {
-		available.Subtract(specified)
+	selected := selectSourceNames(cfg, available).Slice()
+	sort.Strings(selected)
+
+	var names []string
+	for _, name := range selected {
+		if dsc := cfg.GetDataSourceConfig(name); dsc != nil {
+			if creds := dsc.GetCredentials(); creds != nil && creds.Key != "" {
+				name += " (API key configured)"
+			}
+		}
+		names = append(names, name)
 	}
 
+	return names
+}
+
+// Executes the constructors concurrently, no more than max at a time, and
+// returns the services that were successfully created in the original order.
+func buildSources(constructors []func() service.Service, max int) []service.Service {
+	if max <= 0 {
+		max = 1
+	}
+
+	tokenPool := make(chan struct{}, max)
+	for i := 0; i < max; i++ {
+		tokenPool <- struct{}{}
+	}
+
+	var wg sync.WaitGroup
+	results := make([]service.Service, len(constructors))
+	for i, constructor := range constructors {
+		wg.Add(1)
+		<-tokenPool
+
+		go func(idx int, c func() service.Service) {
+			defer func() { tokenPool <- struct{}{} }()
+			defer wg.Done()
+
+			results[idx] = c()
+		}(i, constructor)
+	}
+	wg.Wait()
+
+	var srvs []service.Service
+	for _, srv := range results {
+		if srv != nil {
+			srvs = append(srvs, srv)
+		}
+	}
+	return srvs
+}
+
+// SelectedDataSources uses the config and available data sources to return the selected data sources.
+// Source filter entries wrapped in slashes, such as /^Archive/, are treated as regular expressions.
+func SelectedDataSources(cfg *config.Config, avail []service.Service) []service.Service {
+	names := stringset.New()
+	for _, src := range avail {
+		names.Insert(src.String())
+	}
+	available := selectSourceNames(cfg, names)
+
 	var results []service.Service
 	for _, src := range avail {
 		if available.Has(src.String()) {
@@ -78,6 +250,106 @@ func SelectedDataSources(cfg *config.Config, avail []service.Service) []service.
 	return results
 }
 
+// Removes the data source names not selected by the source filter of the configuration from the available set.
+func selectSourceNames(cfg *config.Config, available stringset.Set) stringset.Set {
+	f := cfg.SourceFilter
+	if len(f.Included) > 0 || len(f.Excluded) > 0 {
+		included := matchSources(f.Included, available)
+
+		if len(f.Excluded) == 0 {
+			available.Intersect(included)
+		} else {
+			// The included sources are added back after the excluded sources have been removed
+			excluded := matchSources(f.Excluded, available)
+
+			available.Subtract(excluded)
+			available.Union(included)
+		}
+	} else if len(f.Sources) > 0 {
+		// An include list without any valid entries selects none of the data sources
+		if matched := matchSources(f.Sources, available); f.Include {
+			available.Intersect(matched)
+		} else {
+			available.Subtract(matched)
+		}
+	}
+	return available
+}
+
+// OrderDataSources returns the data sources in the order selected by the SourceOrder of the configuration,
+// using the number of names provided by each data source in yield for the yield order. The priority and yield
+// orders start the most productive data sources first, which helps enumerations limited in time. Otherwise,
+// the order is unchanged.
+func OrderDataSources(cfg *config.Config, yield map[string]int, srcs []service.Service) []service.Service {
+	if cfg.SourceOrder != config.SourceOrderPriority && cfg.SourceOrder != config.SourceOrderYield {
+		return srcs
+	}
+
+	priority := make(map[string]int, len(srcs))
+	names := make(map[string]int, len(srcs))
+	for _, src := range srcs {
+		if dsc := cfg.GetDataSourceConfig(src.String()); dsc != nil {
+			priority[src.String()] = dsc.Priority
+		}
+		if cfg.SourceOrder == config.SourceOrderYield {
+			names[src.String()] = yield[src.String()]
+		}
+	}
+
+	ordered := append([]service.Service(nil), srcs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i].String(), ordered[j].String()
+
+		if priority[a] != priority[b] {
+			return priority[a] > priority[b]
+		}
+		return names[a] > names[b]
+	})
+	return ordered
+}
+
+// Returns the names from the available set that match the source filter entries.
+func matchSources(entries []string, available stringset.Set) stringset.Set {
+	specified, patterns := parseSourceFilter(entries)
+
+	matched := stringset.New()
+	for name := range available {
+		if specified.Has(name) || matchesAnyPattern(name, patterns) {
+			matched.Insert(name)
+		}
+	}
+	return matched
+}
+
+// Splits the source filter entries into exact data source names and compiled regular expressions.
+// The entries have been validated by the configuration, so any invalid expressions are skipped.
+func parseSourceFilter(entries []string) (stringset.Set, []*regexp.Regexp) {
+	names := stringset.New()
+	var patterns []*regexp.Regexp
+
+	for _, entry := range entries {
+		re, err := config.SourceFilterRegexp(entry)
+		if err != nil {
+			continue
+		} else if re == nil {
+			names.Insert(strings.TrimSpace(entry))
+			continue
+		}
+		patterns = append(patterns, re)
+	}
+
+	return names, patterns
+}
+
+func matchesAnyPattern(name string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
 // ContextConfigBus extracts the Config and EventBus references from the Context argument.
 func ContextConfigBus(ctx context.Context) (*config.Config, *eventbus.EventBus, error) {
 	var ok bool
@@ -106,22 +378,260 @@ func ContextConfigBus(ctx context.Context) (*config.Config, *eventbus.EventBus,
 }
 
 func genNewNameEvent(ctx context.Context, sys systems.System, srv service.Service, name string) {
+	genNewNameEventWithAddrs(ctx, sys, srv, name, nil, false)
+}
+
+// Sends the name along with the IP addresses that the data source provided for it. The addresses
+// are attached to the request as A and AAAA records. When current is true, the addresses are the
+// current resolution of the name, so the name does not need to be resolved again. The addresses
+// from the history of the name, such as those of passive DNS, are only kept for correlation.
+func genNewNameEventWithAddrs(ctx context.Context, sys systems.System, srv service.Service, name string, addrs []string, current bool) {
+	genNewNameRequest(ctx, sys, srv, &requests.DNSRequest{
+		Name:         name,
+		Records:      addrRecords(name, addrs),
+		CurrentAddrs: current,
+	})
+}
+
+// Sends the request for the name provided by the data source, after filling in the domain, tag and source.
+// This allows the data source to attach the additional information it has for the name to the request.
+func genNewNameRequest(ctx context.Context, sys systems.System, srv service.Service, req *requests.DNSRequest) {
 	cfg, bus, err := ContextConfigBus(ctx)
 	if err != nil {
 		return
 	}
+	// Names generated after the enumeration was cancelled, such as the remainder
+	// of a brute forcing wordlist, are no longer sent into the pipeline
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+
+	name := req.Name
+	domain := cfg.WhichDomain(name)
+	if domain == "" {
+		if sys != nil && sys.Pivots() != nil {
+			if name != "" && anyNameRE.FindString(name) == name {
+				sys.Pivots().Dropped(name, srv.String(), recordAddrs(req.Records))
+			}
+		}
+		return
+	}
+	// The blocklist applies to every data source, so the matches are only logged for verbose output
+	if rule := cfg.BlockedBy(name); rule != "" {
+		if cfg.Verbose {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("%s: %s was dropped by the blocklist entry %s", srv.String(), name, rule))
+		}
+		return
+	}
+	// Skip names that another data source already provided during this enumeration. The data
+	// source is still attributed, so the output shows every source that found the name
+	provided, dedup := ctx.Value(requests.ContextNameSources).(*systems.NameSources)
+	if dedup && provided.Has(name) {
+		provided.Add(name, srv.String())
+		return
+	}
+	// The name is only marked as provided once the maximum of the data source accepted it,
+	// so the other data sources can still provide the names dropped by the maximum
+	if !acceptName(ctx, cfg, bus, srv) || (dedup && !provided.Add(name, srv.String())) {
+		return
+	}
+
+	req.Domain = domain
+	req.Tag = srv.Description()
+	req.Source = srv.String()
+	bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, req)
+
+	if sys != nil {
+		sys.Metrics().Record(srv.String(), systems.SourceMetrics{Names: 1})
+
+		if s := sys.NameStream(); s != nil {
+			if err := s.Write(req); err != nil {
+				cfg.Log.Printf("Failed to write %s to the name stream: %v", name, err)
+			}
+		}
 
-	if domain := cfg.WhichDomain(name); domain != "" {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-			Name:   name,
-			Domain: domain,
-			Tag:    srv.Description(),
-			Source: srv.String(),
+		if c := sys.Candidates(); c != nil {
+			c.Emitted(name, srv.String())
+		}
+		if p := sys.Pivots(); p != nil {
+			p.InScope(recordAddrs(req.Records))
+		}
+	}
+}
+
+// Returns the addresses within the A and AAAA records.
+func recordAddrs(records []requests.DNSAnswer) []string {
+	var addrs []string
+
+	for _, r := range records {
+		if r.Type == 1 || r.Type == 28 {
+			addrs = append(addrs, r.Data)
+		}
+	}
+	return addrs
+}
+
+// Returns the A and AAAA records for the valid and publicly routable addresses of the name.
+func addrRecords(name string, addrs []string) []requests.DNSAnswer {
+	var records []requests.DNSAnswer
+
+	for _, addr := range stringset.Deduplicate(addrs) {
+		ip := net.ParseIP(strings.TrimSpace(addr))
+		if ip == nil {
+			continue
+		}
+		if reserved, _ := amassnet.IsReservedAddress(ip.String()); reserved {
+			continue
+		}
+
+		rtype := 1
+		if ip.To4() == nil {
+			rtype = 28
+		}
+		records = append(records, requests.DNSAnswer{
+			Name: name,
+			Type: rtype,
+			Data: ip.String(),
 		})
 	}
+	return records
 }
 
+// Returns the Context used for the web requests of the data source, which carries the data source
+// name and the HTTP request timeout declared by the data source, unless the configuration overrides it.
+// The number of retries configured for the data source replaces the global retry policy, and each
+// request made with the Context is recorded in the metrics of the System.
+func withSourceContext(ctx context.Context, sys systems.System, srv service.Service, declared time.Duration) context.Context {
+	timeout := declared
+	if cfg, _, err := ContextConfigBus(ctx); err == nil {
+		if dsc := cfg.GetDataSourceConfig(srv.String()); dsc != nil {
+			if dsc.Timeout > 0 {
+				timeout = time.Duration(dsc.Timeout) * time.Second
+			}
+			ctx = http.WithRequestRetries(ctx, dsc.Retries)
+		}
+	}
+
+	// The requests are sent with the transport of the System, which can serve recorded responses
+	if sys != nil {
+		ctx = http.WithRequestTransport(ctx, sys.Config().Transport)
+	}
+	ctx = http.WithRequestObserver(ctx, func(err error) {
+		recordRequest(sys, srv, err)
+	})
+	return http.WithRequestSource(http.WithRequestTimeout(ctx, timeout), srv.String())
+}
+
+// Records that the data source began handling a request, and returns the time it started.
+func requestStarted(sys systems.System, srv service.Service) time.Time {
+	if sys != nil {
+		sys.Metrics().Started(srv.String())
+	}
+	return time.Now()
+}
+
+// Records the wall-clock time spent by the data source handling a request started by requestStarted.
+func recordDuration(sys systems.System, srv service.Service, start time.Time) {
+	if sys != nil {
+		sys.Metrics().Finished(srv.String())
+		sys.Metrics().Record(srv.String(), systems.SourceMetrics{Duration: time.Since(start)})
+	}
+}
+
+// Records a web request made by the data source and whether it failed.
+func recordRequest(sys systems.System, srv service.Service, err error) {
+	if sys == nil {
+		return
+	}
+
+	sm := systems.SourceMetrics{Requests: 1}
+	if err != nil {
+		sm.Errors = 1
+	}
+	sys.Metrics().Record(srv.String(), sm)
+}
+
+// Returns the maximum number of names the data source is permitted to provide, or zero when unlimited.
+func maxSourceNames(cfg *config.Config, srv service.Service) int {
+	if dsc := cfg.GetDataSourceConfig(srv.String()); dsc != nil && dsc.MaxNames > 0 {
+		return dsc.MaxNames
+	}
+	return cfg.MaxSourceNames
+}
+
+// Counts the names provided by the data source and reports whether the name is within the cap.
+// The names are counted by the enumeration of the Context, and the cap being reached is logged
+// once for each data source.
+func acceptName(ctx context.Context, cfg *config.Config, bus *eventbus.EventBus, srv service.Service) bool {
+	max := maxSourceNames(cfg, srv)
+	if max <= 0 {
+		return true
+	}
+
+	counts, ok := ctx.Value(requests.ContextNameCounts).(*systems.NameCounts)
+	if !ok || counts == nil {
+		return true
+	}
+
+	count, accepted := counts.Accept(srv.String(), max)
+	if count == max {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("%s: Reached the maximum of %d names, so additional names are dropped", srv.String(), max))
+	}
+	return accepted
+}
+
+// rateLimiter enforces the maximum number of requests per second provided by the configuration.
+// It's embedded in the data sources, so each service instance keeps its own limit and the time of
+// its last request, and the sources built for another System or a source test are not affected.
+type rateLimiter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+// configuredRateLimiter is implemented by the data sources that embed a rateLimiter.
+type configuredRateLimiter interface {
+	setConfiguredRateLimit(persec float64)
+	takeConfiguredRateLimit() bool
+}
+
+// Sets the configured rate limit of the data source, or removes it when persec is not a positive number.
+func (r *rateLimiter) setConfiguredRateLimit(persec float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.interval = 0
+	if persec > 0 {
+		r.interval = time.Duration(float64(time.Second) / persec)
+	}
+}
+
+// Blocks until the next request is permitted by the configured rate limit,
+// and returns false without blocking when no limit has been configured.
+func (r *rateLimiter) takeConfiguredRateLimit() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.interval <= 0 {
+		return false
+	}
+	if wait := time.Until(r.last.Add(r.interval)); wait > 0 {
+		time.Sleep(wait)
+	}
+	r.last = time.Now()
+	return true
+}
+
+// The configured rate limit takes the place of the built-in rate of the data source, and permits
+// one request each time it is taken. The number of checks only weighs the built-in rate.
 func numRateLimitChecks(srv service.Service, num int) {
+	if r, ok := srv.(configuredRateLimiter); ok && r.takeConfiguredRateLimit() {
+		return
+	}
 	for i := 0; i < num; i++ {
 		srv.CheckRateLimit()
 	}
@@ -0,0 +1,394 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+	"github.com/caffix/stringset"
+)
+
+// The System used by the data sources under test, which provides the configuration and metrics.
+type stubSystem struct {
+	systems.System
+	cfg     *config.Config
+	metrics *systems.Metrics
+	pivots  *systems.Pivots
+}
+
+func newStubSystem(cfg *config.Config) *stubSystem {
+	return &stubSystem{
+		cfg:     cfg,
+		metrics: systems.NewMetrics(),
+	}
+}
+
+func (s *stubSystem) Config() *config.Config              { return s.cfg }
+func (s *stubSystem) Metrics() *systems.Metrics           { return s.metrics }
+func (s *stubSystem) Candidates() *systems.Candidates     { return nil }
+func (s *stubSystem) SourceGroups() *systems.SourceGroups { return nil }
+func (s *stubSystem) Pivots() *systems.Pivots             { return s.pivots }
+func (s *stubSystem) NameStream() *systems.NameStream     { return nil }
+
+// Returns a Context for the requests sent to data sources, and a function that returns the names
+// published by the data sources once the expected number arrived or a few seconds have passed.
+func sourceTestContext(cfg *config.Config) (context.Context, func(expected int) []string, func()) {
+	bus := eventbus.NewEventBus()
+
+	var lock sync.Mutex
+	var names []string
+	ready := make(chan struct{})
+	var readyOnce sync.Once
+	collect := func(req *requests.DNSRequest) {
+		// The requests without a name show that the subscription is in place
+		if req.Name == "" {
+			readyOnce.Do(func() { close(ready) })
+			return
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		names = append(names, req.Name)
+	}
+	bus.Subscribe(requests.NewNameTopic, collect)
+	// The events published before the bus processes the subscription are dropped
+	for subscribed := false; !subscribed; {
+		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{})
+
+		select {
+		case <-ready:
+			subscribed = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, cfg)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+	results := func(expected int) []string {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+			lock.Lock()
+			count := len(names)
+			lock.Unlock()
+			if count >= expected {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		// Names beyond the expected number are given a moment to arrive as well
+		time.Sleep(100 * time.Millisecond)
+
+		lock.Lock()
+		defer lock.Unlock()
+		sort.Strings(names)
+		return append([]string(nil), names...)
+	}
+	return ctx, results, bus.Stop
+}
+
+type stubSource struct {
+	service.BaseService
+	rateLimiter
+}
+
+func newStubSource(name string) *stubSource {
+	s := new(stubSource)
+
+	s.BaseService = *service.NewBaseService(s, name)
+	return s
+}
+
+func TestAcceptNamePerEnumeration(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.MaxSourceNames = 2
+	bus := eventbus.NewEventBus()
+	defer bus.Stop()
+	srv := newStubSource("Crtsh")
+
+	first := context.WithValue(context.Background(), requests.ContextNameCounts, systems.NewNameCounts())
+	for i := 0; i < 2; i++ {
+		if !acceptName(first, cfg, bus, srv) {
+			t.Errorf("Name %d was dropped within the maximum", i+1)
+		}
+	}
+	if acceptName(first, cfg, bus, srv) {
+		t.Error("The name beyond the maximum was accepted")
+	}
+
+	// The names counted by a previous enumeration on the same System do not apply
+	second := context.WithValue(context.Background(), requests.ContextNameCounts, systems.NewNameCounts())
+	if !acceptName(second, cfg, bus, srv) {
+		t.Error("The maximum reached by the previous enumeration was applied")
+	}
+}
+
+func TestGenNewNameRequestPivots(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("example.com")
+	ctx, _, stop := sourceTestContext(cfg)
+	defer stop()
+
+	sys := newStubSystem(cfg)
+	sys.pivots = systems.NewPivots(true)
+	srv := newStubSource("Stub")
+	for _, name := range []string{"", "www.other.org", "www.example.com"} {
+		genNewNameRequest(ctx, sys, srv, &requests.DNSRequest{Name: name})
+	}
+
+	expected := map[string][]string{"www.other.org": {"stub"}}
+	if got := sys.pivots.Candidates(cfg.Domains()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("The pivot candidates were %v instead of %v", got, expected)
+	}
+}
+
+func TestGenNewNameRequestDeduplicate(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("example.com")
+	ctx, names, stop := sourceTestContext(cfg)
+	defer stop()
+
+	sys := newStubSystem(cfg)
+	// The names filtered by the first enumeration are provided again during the second
+	provided := systems.NewNameSources()
+	first := context.WithValue(ctx, requests.ContextNameSources, provided)
+	second := context.WithValue(ctx, requests.ContextNameSources, systems.NewNameSources())
+	for _, c := range []context.Context{first, first, second} {
+		for _, src := range []service.Service{newStubSource("First"), newStubSource("Second")} {
+			genNewNameRequest(c, sys, src, &requests.DNSRequest{Name: "www.example.com"})
+		}
+	}
+
+	if got := names(2); len(got) != 2 {
+		t.Errorf("The deduplicated names were provided %d times instead of 2: %v", len(got), got)
+	}
+	// The data source of the dropped name is attributed without collecting the candidate names
+	if srcs := provided.Sources("www.example.com"); !reflect.DeepEqual(srcs, []string{"First", "Second"}) {
+		t.Errorf("The data sources of the deduplicated name were %v", srcs)
+	}
+}
+
+func TestGenNewNameRequestMaximumBeforeDeduplicate(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("example.com")
+	cfg.MaxSourceNames = 1
+	ctx, names, stop := sourceTestContext(cfg)
+	defer stop()
+
+	ctx = context.WithValue(ctx, requests.ContextNameCounts, systems.NewNameCounts())
+	ctx = context.WithValue(ctx, requests.ContextNameSources, systems.NewNameSources())
+	sys := newStubSystem(cfg)
+	full, other := newStubSource("Full"), newStubSource("Other")
+	genNewNameRequest(ctx, sys, full, &requests.DNSRequest{Name: "mail.example.com"})
+	// The name dropped by the maximum of one data source is still provided by another
+	genNewNameRequest(ctx, sys, full, &requests.DNSRequest{Name: "www.example.com"})
+	genNewNameRequest(ctx, sys, other, &requests.DNSRequest{Name: "www.example.com"})
+
+	expected := []string{"mail.example.com", "www.example.com"}
+	if got := names(len(expected)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("The names provided were %v instead of %v", got, expected)
+	}
+}
+
+func TestConfiguredRateLimitPerRequest(t *testing.T) {
+	srv := newStubSource("Weighted")
+	srv.setConfiguredRateLimit(10)
+
+	// The three requests are weighed with 120 checks of the built-in rate each
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		numRateLimitChecks(srv, 120)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("The three requests at ten per second took %v", elapsed)
+	}
+}
+
+func TestConfiguredRateLimitPerInstance(t *testing.T) {
+	first := newStubSource("Shared")
+	first.setConfiguredRateLimit(0.5)
+	numRateLimitChecks(first, 1)
+	last := first.last
+
+	// The source built again with the same name, such as by a source test, has its own limit
+	second := newStubSource("Shared")
+	second.setConfiguredRateLimit(100)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		numRateLimitChecks(second, 1)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("The second instance was held by the limit of the first for %v", elapsed)
+	}
+	if first.interval != 2*time.Second || !first.last.Equal(last) {
+		t.Errorf("The limit of the first instance was changed by the second")
+	}
+}
+
+// A data source that handles its requests once it is released by the test.
+type blockingSource struct {
+	service.BaseService
+	sys     systems.System
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingSource(sys systems.System, name string, started chan string) *blockingSource {
+	s := &blockingSource{
+		sys:     sys,
+		started: started,
+		release: make(chan struct{}),
+	}
+
+	s.BaseService = *service.NewBaseService(s, name)
+	return s
+}
+
+func (s *blockingSource) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(s.sys, s, requestStarted(s.sys, s))
+
+	s.started <- s.String()
+	<-s.release
+}
+
+func TestRequestDomainsInPriorityOrder(t *testing.T) {
+	defer func(num int) { systems.OrderedSourceConcurrency = num }(systems.OrderedSourceConcurrency)
+	systems.OrderedSourceConcurrency = 2
+
+	cfg := config.NewConfig()
+	cfg.AddDomain("example.com")
+	cfg.SourceOrder = config.SourceOrderPriority
+	sys := newStubSystem(cfg)
+
+	started := make(chan string, 3)
+	var srcs []service.Service
+	for i, name := range []string{"Low", "Middle", "High"} {
+		src := newBlockingSource(sys, name, started)
+		if err := src.Start(); err != nil {
+			t.Fatalf("Failed to start the %s data source: %v", name, err)
+		}
+		defer func() { _ = src.Stop() }()
+		defer close(src.release)
+
+		cfg.GetDataSourceConfig(name).Priority = i
+		srcs = append(srcs, src)
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requests.ContextConfig, cfg))
+	defer cancel()
+	srcs = OrderDataSources(cfg, nil, srcs)
+	systems.RequestDomains(ctx, sys, srcs, cfg.Domains())
+
+	var first []string
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-started:
+			first = append(first, name)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("The data sources of the highest priorities did not receive their requests")
+		}
+	}
+	sort.Strings(first)
+	if !reflect.DeepEqual(first, []string{"High", "Middle"}) {
+		t.Errorf("The data sources %v received the requests first instead of High and Middle", first)
+	}
+	// The data source of the lowest priority waits until one of the others is finished
+	select {
+	case name := <-started:
+		t.Errorf("The %s data source received its request before the others were finished", name)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	srcs[0].(*blockingSource).release <- struct{}{}
+	select {
+	case name := <-started:
+		if name != "Low" {
+			t.Errorf("The %s data source received another request", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("The data source of the lowest priority did not receive its request")
+	}
+}
+
+// Receives the lines written to a log.
+type logLines chan string
+
+func (l logLines) Write(p []byte) (int, error) {
+	l <- string(p)
+	return len(p), nil
+}
+
+func TestWithSourcesDeadlineInProgress(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SourcesTimeout = 200 * time.Millisecond
+	lines := make(logLines, 10)
+	cfg.Log = log.New(lines, "", 0)
+	sys := newStubSystem(cfg)
+
+	started := make(chan string, 1)
+	busy := newBlockingSource(sys, "Busy", started)
+	idle := newBlockingSource(sys, "Idle", started)
+	for _, src := range []*blockingSource{busy, idle} {
+		if err := src.Start(); err != nil {
+			t.Fatalf("Failed to start the %s data source: %v", src.String(), err)
+		}
+		defer func(src *blockingSource) { _ = src.Stop() }(src)
+		defer close(src.release)
+	}
+
+	ctx, cancel := systems.WithSourcesDeadline(context.Background(), sys, []service.Service{busy, idle})
+	defer cancel()
+	// The request is no longer queued once the data source handles it
+	busy.Request(ctx, &requests.DNSRequest{Name: "example.com", Domain: "example.com"})
+	<-started
+
+	for {
+		select {
+		case line := <-lines:
+			if strings.HasPrefix(line, "Abandoned") {
+				if !strings.Contains(line, "Busy") || strings.Contains(line, "Idle") {
+					t.Errorf("The abandoned data sources were not limited to the request in progress: %s", line)
+				}
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("The data source with the request in progress was not logged as abandoned")
+			return
+		}
+	}
+}
+
+func TestSelectSourceNamesInvalidIncluded(t *testing.T) {
+	available := func() stringset.Set {
+		return stringset.New("AlienVault", "ArchiveIt", "Crtsh")
+	}
+
+	cfg := config.NewConfig()
+	cfg.SourceFilter.Include = true
+	cfg.SourceFilter.Sources = []string{"/^Archive(/", "/[/"}
+	if got := selectSourceNames(cfg, available()); got.Len() != 0 {
+		t.Errorf("The include filter made only of invalid expressions selected %v", got.Slice())
+	}
+
+	cfg.SourceFilter.Include = false
+	if got := selectSourceNames(cfg, available()); got.Len() != 3 {
+		t.Errorf("The exclude filter made only of invalid expressions removed data sources: %v", got.Slice())
+	}
+
+	cfg.SourceFilter.Sources = nil
+	cfg.SourceFilter.Included = []string{"/^Archive(/"}
+	if got := selectSourceNames(cfg, available()); got.Len() != 0 {
+		t.Errorf("The included list made only of invalid expressions selected %v", got.Slice())
+	}
+}
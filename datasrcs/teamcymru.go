@@ -25,6 +25,7 @@ import (
 // TeamCymru is the Service that handles access to the TeamCymru data source.
 type TeamCymru struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -54,6 +55,9 @@ func (t *TeamCymru) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (t *TeamCymru) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(t.sys, t, requestStarted(t.sys, t))
+	ctx = withSourceContext(ctx, t.sys, t, 0)
+
 	if req, ok := args.(*requests.ASNRequest); ok {
 		t.asnRequest(ctx, req)
 	}
@@ -69,13 +73,13 @@ func (t *TeamCymru) asnRequest(ctx context.Context, req *requests.ASNRequest) {
 		return
 	}
 
-	t.CheckRateLimit()
+	numRateLimitChecks(t, 1)
 	r := t.origin(ctx, req.Address)
 	if r == nil {
 		return
 	}
 
-	t.CheckRateLimit()
+	numRateLimitChecks(t, 1)
 	asn := t.asnLookup(ctx, r.ASN)
 	if asn == nil {
 		return
@@ -23,6 +23,7 @@ import (
 // Twitter is the Service that handles access to the Twitter data source.
 type Twitter struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -69,9 +70,7 @@ func (t *Twitter) OnStart() error {
 
 // CheckConfig implements the Service interface.
 func (t *Twitter) checkConfig() error {
-	creds := t.sys.Config().GetDataSourceConfig(t.String()).GetCredentials()
-
-	if creds == nil || creds.Key == "" || creds.Secret == "" {
+	if !t.RequiredConfigPresent(t.sys.Config()) {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", t.String())
 		t.sys.Config().Log.Print(estr)
 		return errors.New(estr)
@@ -80,8 +79,16 @@ func (t *Twitter) checkConfig() error {
 	return nil
 }
 
+// RequiredConfigPresent implements the ConfiguredService interface.
+func (t *Twitter) RequiredConfigPresent(cfg *config.Config) bool {
+	return apiKeyPresent(cfg, t.String(), true)
+}
+
 // OnRequest implements the Service interface.
 func (t *Twitter) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(t.sys, t, requestStarted(t.sys, t))
+	ctx = withSourceContext(ctx, t.sys, t, 0)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		t.dnsRequest(ctx, req)
 	}
@@ -98,6 +105,7 @@ func (t *Twitter) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 
+	re = pivotNameRegexp(t.sys, re)
 	numRateLimitChecks(t, 2)
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 		fmt.Sprintf("Querying %s for %s subdomains", t.String(), req.Domain))
@@ -129,7 +137,8 @@ func (t *Twitter) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 
 func (t *Twitter) getBearerToken() (string, error) {
 	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded;charset=UTF-8"}
-	page, err := http.RequestWebPage(context.Background(), "https://api.twitter.com/oauth2/token",
+	ctx := http.WithRequestTransport(context.Background(), t.sys.Config().Transport)
+	page, err := http.RequestWithRetry(ctx, "https://api.twitter.com/oauth2/token",
 		strings.NewReader("grant_type=client_credentials"), headers,
 		&http.BasicAuth{
 			Username: t.creds.Key,
@@ -25,6 +25,7 @@ import (
 // Umbrella is the Service that handles access to the Umbrella data source.
 type Umbrella struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -61,9 +62,7 @@ func (u *Umbrella) OnStart() error {
 
 // CheckConfig implements the Service interface.
 func (u *Umbrella) checkConfig() error {
-	creds := u.sys.Config().GetDataSourceConfig(u.String()).GetCredentials()
-
-	if creds == nil || creds.Key == "" {
+	if !u.RequiredConfigPresent(u.sys.Config()) {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", u.String())
 		u.sys.Config().Log.Print(estr)
 		return errors.New(estr)
@@ -72,8 +71,16 @@ func (u *Umbrella) checkConfig() error {
 	return nil
 }
 
+// RequiredConfigPresent implements the ConfiguredService interface.
+func (u *Umbrella) RequiredConfigPresent(cfg *config.Config) bool {
+	return apiKeyPresent(cfg, u.String(), false)
+}
+
 // OnRequest implements the Service interface.
 func (u *Umbrella) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(u.sys, u, requestStarted(u.sys, u))
+	ctx = withSourceContext(ctx, u.sys, u, 0)
+
 	switch req := args.(type) {
 	case *requests.DNSRequest:
 		u.dnsRequest(ctx, req)
@@ -101,7 +108,7 @@ func (u *Umbrella) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	headers := u.restHeaders()
 	url := u.restDNSURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -135,7 +142,7 @@ func (u *Umbrella) addrRequest(ctx context.Context, req *requests.AddrRequest) {
 
 	headers := u.restHeaders()
 	url := u.restAddrURL(req.Address)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -180,7 +187,7 @@ func (u *Umbrella) executeASNAddrQuery(ctx context.Context, req *requests.ASNReq
 
 	headers := u.restHeaders()
 	url := u.restAddrToASNURL(req.Address)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -229,7 +236,7 @@ func (u *Umbrella) executeASNAddrQuery(ctx context.Context, req *requests.ASNReq
 		req.Netblocks = stringset.New()
 		req.Netblocks.Insert(strings.TrimSpace(req.Prefix))
 
-		u.CheckRateLimit()
+		numRateLimitChecks(u, 1)
 		u.executeASNQuery(ctx, req)
 	}
 	bus.Publish(requests.NewASNTopic, eventbus.PriorityHigh, req)
@@ -243,7 +250,7 @@ func (u *Umbrella) executeASNQuery(ctx context.Context, req *requests.ASNRequest
 
 	headers := u.restHeaders()
 	url := u.restASNToCIDRsURL(req.ASN)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -278,7 +285,7 @@ func (u *Umbrella) executeASNQuery(ctx context.Context, req *requests.ASNRequest
 			req.Address = addr.String()
 			req.CC = netblock[0].Geo.CountryCode
 
-			u.CheckRateLimit()
+			numRateLimitChecks(u, 1)
 			u.executeASNAddrQuery(ctx, req)
 			return
 		}
@@ -348,8 +355,8 @@ func (u *Umbrella) queryWhois(ctx context.Context, domain string) *whoisRecord {
 	headers := u.restHeaders()
 	whoisURL := u.whoisRecordURL(domain)
 
-	u.CheckRateLimit()
-	record, err := http.RequestWebPage(ctx, whoisURL, nil, headers, nil)
+	numRateLimitChecks(u, 1)
+	record, err := http.RequestWithRetry(ctx, whoisURL, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), whoisURL, err))
 		return nil
@@ -375,9 +382,9 @@ func (u *Umbrella) queryReverseWhois(ctx context.Context, apiURL string) []strin
 
 	// Umbrella provides data in 500 piece chunks
 	for count, more := 0, true; more; count = count + 500 {
-		u.CheckRateLimit()
+		numRateLimitChecks(u, 1)
 		fullAPIURL := fmt.Sprintf("%s&offset=%d", apiURL, count)
-		record, err := http.RequestWebPage(ctx, fullAPIURL, nil, headers, nil)
+		record, err := http.RequestWithRetry(ctx, fullAPIURL, nil, headers, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), apiURL, err))
 			return domains.Slice()
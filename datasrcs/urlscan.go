@@ -8,20 +8,30 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	neturl "net/url"
 	"strings"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringfilter"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/service"
 	"github.com/caffix/stringset"
 )
 
+const (
+	// The number of results requested with each page of the search
+	urlscanPageSize = 100
+	// The number of search results obtained, unless the max_results option is provided
+	urlscanMaxResults = 1000
+)
+
 // URLScan is the Service that handles access to the URLScan data source.
 type URLScan struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -58,6 +68,9 @@ func (u *URLScan) OnStart() error {
 
 // OnRequest implements the Service interface.
 func (u *URLScan) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(u.sys, u, requestStarted(u.sys, u))
+	ctx = withSourceContext(ctx, u.sys, u, 0)
+
 	if req, ok := args.(*requests.DNSRequest); ok {
 		u.dnsRequest(ctx, req)
 	}
@@ -74,83 +87,175 @@ func (u *URLScan) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 
-	numRateLimitChecks(u, 1)
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 		fmt.Sprintf("Querying %s for %s subdomains", u.String(), req.Domain))
 
-	url := u.searchURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
-	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
-		return
+	subs := stringset.New()
+	ids := u.search(ctx, req.Domain, subs)
+	if len(ids) == 0 {
+		if id := u.attemptSubmission(ctx, req.Domain); id != "" {
+			ids = []string{id}
+		}
 	}
-	// Extract the subdomain names from the REST API results
-	var results struct {
-		Results []struct {
-			ID string `json:"_id"`
-		} `json:"results"`
-		Total int `json:"total"`
+
+	reqs := u.requestsDataset()
+	for _, id := range ids {
+		set, err := u.getSubsFromResult(ctx, id, reqs)
+		if err != nil {
+			break
+		}
+
+		subs.Union(set)
 	}
-	if err := json.Unmarshal([]byte(page), &results); err != nil {
-		return
+
+	for name := range stringfilter.FilterMatching(subs, pivotNameRegexp(u.sys, re)) {
+		genNewNameEvent(ctx, u.sys, u, name)
 	}
+}
 
+// Pages through the search results using the search_after cursor, inserting the page and task
+// domains into the set and returning the identifiers of the results.
+func (u *URLScan) search(ctx context.Context, domain string, subs stringset.Set) []string {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return nil
+	}
+
+	limit := urlscanMaxResults
+	if dsc := u.sys.Config().GetDataSourceConfig(u.String()); dsc != nil && dsc.MaxResults > 0 {
+		limit = dsc.MaxResults
+	}
+
+	var after string
 	var ids []string
-	if results.Total > 0 {
+	for len(ids) < limit {
+		select {
+		case <-ctx.Done():
+			return ids
+		default:
+		}
+
+		numRateLimitChecks(u, 1)
+		url := u.searchURL(domain, after)
+		page, err := http.RequestWithRetry(ctx, url, nil, u.headers(), nil)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+			break
+		}
+		// Extract the subdomain names from the REST API results
+		var results struct {
+			Results []struct {
+				ID   string `json:"_id"`
+				Page struct {
+					Domain string `json:"domain"`
+				} `json:"page"`
+				Task struct {
+					Domain string `json:"domain"`
+				} `json:"task"`
+				Sort []json.RawMessage `json:"sort"`
+			} `json:"results"`
+			HasMore bool `json:"has_more"`
+		}
+		if err := json.Unmarshal([]byte(page), &results); err != nil || len(results.Results) == 0 {
+			break
+		}
+
 		for _, result := range results.Results {
 			ids = append(ids, result.ID)
+			subs.InsertMany(strings.ToLower(result.Page.Domain), strings.ToLower(result.Task.Domain))
 		}
-	} else {
-		if id := u.attemptSubmission(ctx, req.Domain); id != "" {
-			ids = []string{id}
-		}
-	}
 
-	subs := stringset.New()
-	for _, id := range ids {
-		set, err := u.getSubsFromResult(ctx, id)
-		if err != nil {
+		last := results.Results[len(results.Results)-1].Sort
+		if !results.HasMore || len(last) == 0 {
 			break
 		}
+		// The cursor is the sort values of the last result, separated by commas
+		var values []string
+		for _, v := range last {
+			values = append(values, strings.Trim(string(v), `"`))
+		}
+		after = strings.Join(values, ",")
+	}
+	return ids
+}
 
-		subs.Union(set)
+// Returns true when the datasets option adds the requests of the scan results to the links mined by default.
+func (u *URLScan) requestsDataset() bool {
+	dsc := u.sys.Config().GetDataSourceConfig(u.String())
+	if dsc == nil {
+		return false
 	}
 
-	for name := range subs {
-		if re.MatchString(name) {
-			genNewNameEvent(ctx, u.sys, u, name)
+	for _, set := range dsc.Datasets {
+		if strings.ToLower(strings.TrimSpace(set)) == "requests" {
+			return true
 		}
 	}
+	return false
 }
 
-func (u *URLScan) getSubsFromResult(ctx context.Context, id string) (stringset.Set, error) {
+func (u *URLScan) getSubsFromResult(ctx context.Context, id string, reqs bool) (stringset.Set, error) {
 	subs := stringset.New()
 
 	_, bus, err := ContextConfigBus(ctx)
 	if err != nil {
 		return subs, errors.New("Failed to access the event bus")
 	}
+	if ctx.Err() != nil {
+		return subs, ctx.Err()
+	}
 
 	numRateLimitChecks(u, 2)
 	url := u.resultURL(id)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := http.RequestWithRetry(ctx, url, nil, u.headers(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return subs, errors.New("HTTP request failed")
 	}
 	// Extract the subdomain names from the REST API results
 	var data struct {
+		Data struct {
+			Links []struct {
+				Href string `json:"href"`
+			} `json:"links"`
+			Requests []struct {
+				Request struct {
+					Request struct {
+						URL string `json:"url"`
+					} `json:"request"`
+				} `json:"request"`
+			} `json:"requests"`
+		} `json:"data"`
 		Lists struct {
 			IPs        []string `json:"ips"`
+			Domains    []string `json:"domains"`
 			Subdomains []string `json:"linkDomains"`
 		} `json:"lists"`
 	}
-	if err := json.Unmarshal([]byte(page), &data); err == nil {
-		subs.InsertMany(data.Lists.Subdomains...)
+	if err := json.Unmarshal([]byte(page), &data); err != nil {
+		return subs, nil
+	}
+
+	subs.InsertMany(data.Lists.Subdomains...)
+	for _, link := range data.Data.Links {
+		subs.Insert(urlHostname(link.Href))
+	}
+	if reqs {
+		subs.InsertMany(data.Lists.Domains...)
+		for _, r := range data.Data.Requests {
+			subs.Insert(urlHostname(r.Request.Request.URL))
+		}
 	}
 	return subs, nil
 }
 
+func urlHostname(u string) string {
+	if parsed, err := neturl.Parse(u); err == nil {
+		return strings.ToLower(parsed.Hostname())
+	}
+	return ""
+}
+
 func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 	_, bus, err := ContextConfigBus(ctx)
 	if err != nil {
@@ -163,13 +268,11 @@ func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 
 	numRateLimitChecks(u, 2)
 
-	headers := map[string]string{
-		"API-Key":      u.creds.Key,
-		"Content-Type": "application/json",
-	}
+	headers := u.headers()
+	headers["Content-Type"] = "application/json"
 	url := "https://urlscan.io/api/v1/scan/"
 	body := strings.NewReader(u.submitBody(domain))
-	page, err := http.RequestWebPage(ctx, url, body, headers, nil)
+	page, err := http.RequestWithRetry(ctx, url, body, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return ""
@@ -189,8 +292,8 @@ func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 	}
 
 	// Keep this data source active while waiting for the scan to complete
-	for {
-		_, err = http.RequestWebPage(ctx, result.API, nil, nil, nil)
+	for ctx.Err() == nil {
+		_, err = http.RequestWithRetry(ctx, result.API, nil, nil, nil)
 		if err == nil || err.Error() != "404 Not Found" {
 			break
 		}
@@ -200,8 +303,21 @@ func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 	return result.ID
 }
 
-func (u *URLScan) searchURL(domain string) string {
-	return fmt.Sprintf("https://urlscan.io/api/v1/search/?q=domain:%s", domain)
+// Returns the headers that provide the API key, which raises the rate limits of the account.
+func (u *URLScan) headers() map[string]string {
+	headers := make(map[string]string)
+	if u.creds != nil && u.creds.Key != "" {
+		headers["API-Key"] = u.creds.Key
+	}
+	return headers
+}
+
+func (u *URLScan) searchURL(domain, after string) string {
+	url := fmt.Sprintf("https://urlscan.io/api/v1/search/?q=domain:%s&size=%d", domain, urlscanPageSize)
+	if after != "" {
+		url += "&search_after=" + neturl.QueryEscape(after)
+	}
+	return url
 }
 
 func (u *URLScan) resultURL(id string) string {
@@ -22,6 +22,7 @@ import (
 // WhoisXML is the Service that handles access to the WhoisXML data source.
 type WhoisXML struct {
 	service.BaseService
+	rateLimiter
 
 	SourceType string
 	sys        systems.System
@@ -89,9 +90,7 @@ func (w *WhoisXML) OnStart() error {
 
 // CheckConfig implements the Service interface.
 func (w *WhoisXML) checkConfig() error {
-	creds := w.sys.Config().GetDataSourceConfig(w.String()).GetCredentials()
-
-	if creds == nil || creds.Key == "" {
+	if !w.RequiredConfigPresent(w.sys.Config()) {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", w.String())
 		w.sys.Config().Log.Print(estr)
 		return errors.New(estr)
@@ -100,8 +99,16 @@ func (w *WhoisXML) checkConfig() error {
 	return nil
 }
 
+// RequiredConfigPresent implements the ConfiguredService interface.
+func (w *WhoisXML) RequiredConfigPresent(cfg *config.Config) bool {
+	return apiKeyPresent(cfg, w.String(), false)
+}
+
 // OnRequest implements the Service interface.
 func (w *WhoisXML) OnRequest(ctx context.Context, args service.Args) {
+	defer recordDuration(w.sys, w, requestStarted(w.sys, w))
+	ctx = withSourceContext(ctx, w.sys, w, 0)
+
 	if req, ok := args.(*requests.WhoisRequest); ok {
 		w.whoisRequest(ctx, req)
 	}
@@ -133,7 +140,7 @@ func (w *WhoisXML) whoisRequest(ctx context.Context, req *requests.WhoisRequest)
 	r.SearchTerms.Include = append(r.SearchTerms.Include, req.Domain)
 	jr, _ := json.Marshal(r)
 
-	page, err := http.RequestWebPage(ctx, u, bytes.NewReader(jr), headers, nil)
+	page, err := http.RequestWithRetry(ctx, u, bytes.NewReader(jr), headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", w.String(), u, err))
 		return
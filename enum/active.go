@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/OWASP/Amass/v3/datasrcs"
 	"github.com/OWASP/Amass/v3/net/http"
@@ -130,14 +131,26 @@ func (a *activeTask) crawlName(ctx context.Context, req *requests.DNSRequest, tp
 	tp.NewData() <- req
 	defer func() { tp.ProcessedData() <- req }()
 
+	crawls := a.enum.Sys.CrawlLimiter()
+	if !crawls.Acquire(ctx) {
+		return
+	}
+	defer crawls.Release()
+
 	cfg := a.enum.Config
+	opts := http.NewCrawlOptions(time.Duration(cfg.CrawlTimeout)*time.Second,
+		time.Duration(cfg.CrawlRequestDelay)*time.Millisecond,
+		cfg.CrawlConcurrentRequests, cfg.CrawlMaxRequests, cfg.HonorRobotsTxt)
+	opts.MaxLinks = 50
+
 	for _, port := range cfg.Ports {
 		u := "https://" + req.Name
 		if port != 443 {
 			u = u + ":" + strconv.Itoa(port)
 		}
 
-		names, err := http.Crawl(ctx, u, cfg.Domains(), 50, a.enum.crawlFilter)
+		names, err := http.CrawlWithOptions(http.WithRequestTransport(ctx, cfg.Transport),
+			u, cfg.Domains(), a.enum.crawlFilter, opts)
 		if err != nil {
 			if cfg.Verbose {
 				cfg.Log.Printf("Active Crawl: %v", err)
@@ -133,6 +133,21 @@ func (dt *dNSTask) processDNSRequest(ctx context.Context, req *requests.DNSReque
 	if req == nil || !req.Valid() {
 		return nil, nil
 	}
+	// Data sources can provide the current addresses of the names, which makes resolving them again
+	// unnecessary. The addresses from the history of a name can be stale, so the name is resolved instead
+	if providedAddrs(req) {
+		if req.CurrentAddrs {
+			return dt.processProvidedAddrs(ctx, req)
+		}
+		req.Records = nil
+	}
+
+	if limiter := dt.enum.Sys.ResolutionLimiter(); limiter != nil {
+		if !limiter.Acquire(ctx) {
+			return nil, nil
+		}
+		defer limiter.Release()
+	}
 loop:
 	for _, t := range InitialQueryTypes {
 		select {
@@ -181,11 +196,52 @@ loop:
 	}
 
 	if len(req.Records) > 0 {
+		if c := dt.enum.Sys.Candidates(); c != nil {
+			c.Resolved(req.Name)
+		}
 		return req, nil
 	}
 	return nil, nil
 }
 
+// Returns true when the only records of the request are the addresses of the name.
+func providedAddrs(req *requests.DNSRequest) bool {
+	if len(req.Records) == 0 {
+		return false
+	}
+
+	for _, r := range req.Records {
+		if (r.Type != int(dns.TypeA) && r.Type != int(dns.TypeAAAA)) || r.Name != req.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// Accepts the addresses provided by the data source, unless they match a DNS wildcard.
+func (dt *dNSTask) processProvidedAddrs(ctx context.Context, req *requests.DNSRequest) (pipeline.Data, error) {
+	if !requests.TrustedTag(req.Tag) {
+		msg := resolvers.QueryMsg(req.Name, dns.TypeA)
+
+		for _, r := range req.Records {
+			rr, err := dns.NewRR(fmt.Sprintf("%s 0 IN %s %s",
+				dns.Fqdn(req.Name), dns.TypeToString[uint16(r.Type)], r.Data))
+			if err == nil {
+				msg.Answer = append(msg.Answer, rr)
+			}
+		}
+
+		if dt.enum.Sys.Pool().WildcardType(ctx, msg, req.Domain) != resolvers.WildcardTypeNone {
+			return nil, nil
+		}
+	}
+
+	if c := dt.enum.Sys.Candidates(); c != nil {
+		c.Resolved(req.Name)
+	}
+	return req, nil
+}
+
 func (dt *dNSTask) handleResolverError(ctx context.Context, e error) {
 	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
 	if err != nil {
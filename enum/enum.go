@@ -31,11 +31,13 @@ type Enumeration struct {
 	closedOnce     sync.Once
 	logQueue       queue.Queue
 	ctx            context.Context
+	srcCtx         context.Context
 	srcs           []service.Service
 	done           chan struct{}
 	doneOnce       sync.Once
 	resolvedFilter stringfilter.Filter
 	crawlFilter    stringfilter.Filter
+	nameSources    *systems.NameSources
 	nameSrc        *enumSource
 	subTask        *subdomainTask
 	dnsTask        *dNSTask
@@ -48,12 +50,16 @@ func NewEnumeration(cfg *config.Config, sys systems.System) *Enumeration {
 		Sys:            sys,
 		Bus:            eventbus.NewEventBus(),
 		Graph:          graph.NewGraph(graph.NewCayleyGraphMemory()),
-		srcs:           datasrcs.SelectedDataSources(cfg, sys.DataSources()),
+		srcs:           datasrcs.OrderDataSources(cfg, cfg.SourceYield, datasrcs.SelectedDataSources(cfg, sys.DataSources())),
 		logQueue:       queue.NewQueue(),
 		done:           make(chan struct{}),
 		resolvedFilter: stringfilter.NewBloomFilter(filterMaxSize),
 		crawlFilter:    stringfilter.NewStringFilter(),
 	}
+	// The names already provided by another data source are only dropped during this enumeration
+	if cfg.DeduplicateSourceNames {
+		e.nameSources = systems.NewNameSources()
+	}
 
 	if cfg.Passive {
 		return e
@@ -145,7 +151,16 @@ func (e *Enumeration) Start(ctx context.Context) error {
 	ctx, cancel = context.WithCancel(ctx)
 	ctx = context.WithValue(ctx, requests.ContextConfig, e.Config)
 	ctx = context.WithValue(ctx, requests.ContextEventBus, e.Bus)
+	// The maximum number of names provided by each data source applies to this enumeration
+	ctx = context.WithValue(ctx, requests.ContextNameCounts, systems.NewNameCounts())
+	if e.nameSources != nil {
+		ctx = context.WithValue(ctx, requests.ContextNameSources, e.nameSources)
+	}
 	e.ctx = ctx
+	// The data sources are given the Context that expires after the configured maximum duration
+	var srcCancel context.CancelFunc
+	e.srcCtx, srcCancel = systems.WithSourcesDeadline(ctx, e.Sys, e.srcs)
+	defer srcCancel()
 
 	// Monitor for termination of the enumeration
 	go func() {
@@ -164,18 +179,14 @@ func (e *Enumeration) Start(ctx context.Context) error {
 
 	// Release the root domain names to the input source and each data source
 	for _, domain := range e.Config.Domains() {
-		req := &requests.DNSRequest{
+		source.InputName(&requests.DNSRequest{
 			Name:   domain,
 			Domain: domain,
 			Tag:    requests.DNS,
 			Source: "DNS",
-		}
-
-		source.InputName(req)
-		for _, src := range e.srcs {
-			src.Request(ctx, req.Clone().(*requests.DNSRequest))
-		}
+		})
 	}
+	systems.RequestDomains(e.srcCtx, e.Sys, e.srcs, e.Config.Domains())
 
 	// If requests were made for specific ASNs, then those requests are
 	// sent to included data sources at this point
@@ -183,7 +194,7 @@ func (e *Enumeration) Start(ctx context.Context) error {
 		req := &requests.ASNRequest{ASN: asn}
 
 		for _, src := range e.srcs {
-			src.Request(ctx, req.Clone().(*requests.ASNRequest))
+			src.Request(e.srcCtx, req.Clone().(*requests.ASNRequest))
 		}
 	}
 
@@ -4,19 +4,112 @@
 package enum
 
 import (
+	"context"
 	"time"
 
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringfilter"
+	"github.com/caffix/stringset"
 )
 
 // ExtractOutput is a convenience method for obtaining new discoveries made by the enumeration process.
 func (e *Enumeration) ExtractOutput(filter stringfilter.Filter, asinfo bool) []*requests.Output {
+	var outputs []*requests.Output
+
 	if e.Config.Passive {
-		return e.Graph.EventNames(e.Config.UUID.String(), filter)
+		outputs = e.Graph.EventNames(e.Config.UUID.String(), filter)
+	} else {
+		outputs = e.Graph.EventOutput(e.Config.UUID.String(), filter, asinfo, e.Sys.Cache())
 	}
 
-	return e.Graph.EventOutput(e.Config.UUID.String(), filter, asinfo, e.Sys.Cache())
+	// The data sources that provided names already found by another source are only attributed here
+	if e.nameSources != nil {
+		for _, o := range outputs {
+			o.Sources = mergeSources(o.Sources, e.nameSources.Sources(o.Name))
+		}
+	}
+	return outputs
+}
+
+func mergeSources(sources, others []string) []string {
+	set := stringset.New(sources...)
+
+	for _, src := range others {
+		if !set.Has(src) {
+			set.Insert(src)
+			sources = append(sources, src)
+		}
+	}
+	return sources
+}
+
+// Run starts the enumeration in the background and returns a channel that receives each in-scope
+// discovery once. The channel is closed after the enumeration completes or the Context is cancelled.
+// Configuration problems are returned before the enumeration is started, and the errors of the
+// enumeration itself are written to the log of the Config.
+func (e *Enumeration) Run(ctx context.Context) (<-chan *requests.Output, error) {
+	if err := e.Config.CheckSettings(); err != nil {
+		return nil, err
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		if err := e.Start(ctx); err != nil {
+			e.Config.Log.Printf("Enumeration: %v", err)
+		}
+	}()
+
+	// This filter ensures that only new names are sent
+	known := stringfilter.NewBloomFilter(1 << 22)
+	extract := func() []*requests.Output {
+		var outputs []*requests.Output
+
+		for _, o := range e.ExtractOutput(known, true) {
+			if e.Config.IsDomainInScope(o.Name) {
+				outputs = append(outputs, o)
+			}
+		}
+		return outputs
+	}
+
+	out := make(chan *requests.Output, 10)
+	go sendOutput(ctx, out, finished, extract)
+	return out, nil
+}
+
+// Periodically sends the new discoveries returned by extract until the enumeration is finished.
+// The sending stops once the Context is cancelled, so a caller that stops reading the channel
+// after cancelling does not leave the goroutine blocked.
+func sendOutput(ctx context.Context, out chan *requests.Output, finished chan struct{}, extract func() []*requests.Output) {
+	defer close(out)
+
+	send := func() bool {
+		for _, o := range extract() {
+			select {
+			case out <- o:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	}
+
+	t := time.NewTicker(3 * time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-finished:
+			// Check one last time
+			send()
+			return
+		case <-t.C:
+			if !send() {
+				return
+			}
+		}
+	}
 }
 
 func (e *Enumeration) submitKnownNames() {
@@ -0,0 +1,64 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func testOutputs(num int) func() []*requests.Output {
+	return func() []*requests.Output {
+		var outputs []*requests.Output
+
+		for i := 0; i < num; i++ {
+			outputs = append(outputs, &requests.Output{Name: fmt.Sprintf("www%d.example.com", i)})
+		}
+		return outputs
+	}
+}
+
+func TestSendOutput(t *testing.T) {
+	finished := make(chan struct{})
+	close(finished)
+
+	out := make(chan *requests.Output)
+	go sendOutput(context.Background(), out, finished, testOutputs(20))
+
+	var count int
+	for range out {
+		count++
+	}
+	if count != 20 {
+		t.Errorf("Received %d of the 20 discoveries", count)
+	}
+}
+
+func TestSendOutputCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	finished := make(chan struct{})
+	close(finished)
+
+	// The channel is never read, as by a caller that stopped reading after cancelling
+	out := make(chan *requests.Output)
+	done := make(chan struct{})
+	go func() {
+		sendOutput(ctx, out, finished, testOutputs(20))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The output was still being sent after the context was cancelled")
+	}
+	if _, ok := <-out; ok {
+		t.Error("The output channel was not closed after the context was cancelled")
+	}
+}
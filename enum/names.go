@@ -250,9 +250,9 @@ loop:
 		for _, src := range r.enum.srcs {
 			switch v := element.(type) {
 			case *requests.ResolvedRequest:
-				src.Request(r.enum.ctx, v.Clone())
+				src.Request(r.enum.srcCtx, v.Clone())
 			case *requests.SubdomainRequest:
-				src.Request(r.enum.ctx, v.Clone())
+				src.Request(r.enum.srcCtx, v.Clone())
 			default:
 				continue loop
 			}
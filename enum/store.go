@@ -422,7 +422,7 @@ func (dm *dataManager) addrRequest(ctx context.Context, req *requests.AddrReques
 	}
 
 	for _, src := range dm.enum.srcs {
-		src.Request(ctx, &requests.ASNRequest{Address: req.Address})
+		src.Request(dm.enum.srcCtx, &requests.ASNRequest{Address: req.Address})
 	}
 
 	var found bool
@@ -32,8 +32,11 @@ func main() {
 	}
 	defer e.Close()
 
-	e.Start(context.TODO())
-	for _, o := range e.ExtractOutput(nil, false) {
+	output, err := e.Run(context.TODO())
+	if err != nil {
+		return
+	}
+	for o := range output {
 		fmt.Println(o.Name)
 	}
 }
@@ -199,7 +199,8 @@ func censorString(input string, start, end int) string {
 // OutputLineParts returns the parts of a line to be printed for a requests.Output.
 func OutputLineParts(out *requests.Output, src, addrs, demo bool) (source, name, ips string) {
 	if src {
-		source = fmt.Sprintf("%-18s", "["+out.Sources[0]+"] ")
+		// Show every data source that discovered the name
+		source = fmt.Sprintf("%-18s", "["+strings.Join(out.Sources, ", ")+"] ")
 	}
 	if addrs {
 		for i, a := range out.Addresses {
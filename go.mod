@@ -4,6 +4,7 @@ go 1.15
 
 require (
 	github.com/AndreasBriese/bbloom v0.0.0-20190825152654-46b345b51c96
+	github.com/BurntSushi/toml v0.3.1
 	github.com/PuerkitoBio/goquery v1.6.0
 	github.com/caffix/eventbus v0.0.0-20201229201025-4c5f3ce94295
 	github.com/caffix/pipeline v0.0.0-20210106193115-41730a0744af
@@ -46,7 +46,7 @@ func NewCollection(cfg *config.Config, sys systems.System) *Collection {
 		Config: cfg,
 		Bus:    eb.NewEventBus(),
 		Sys:    sys,
-		srcs:   datasrcs.SelectedDataSources(cfg, sys.DataSources()),
+		srcs:   datasrcs.OrderDataSources(cfg, cfg.SourceYield, datasrcs.SelectedDataSources(cfg, sys.DataSources())),
 		Output: make(chan *requests.Output, 100),
 		done:   make(chan struct{}, 2),
 		filter: stringfilter.NewStringFilter(),
@@ -265,11 +265,11 @@ func (c *Collection) ReverseWhois() error {
 	c.ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
 
 	// Send the whois requests to the data sources
-	for _, src := range c.srcs {
+	systems.RequestInOrder(c.ctx, c.Sys, c.srcs, func(src service.Service) {
 		for _, domain := range c.Config.Domains() {
 			src.Request(c.ctx, &requests.WhoisRequest{Domain: domain})
 		}
-	}
+	})
 
 	last := time.Now()
 	t := time.NewTicker(2 * time.Second)
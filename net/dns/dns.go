@@ -36,6 +36,66 @@ func AnySubdomainRegexString() string {
 	return SUBRE + "[a-zA-Z]{2,61}"
 }
 
+// StrictFindString returns the leftmost match of the regular expression in the content that is not
+// embedded within a longer token. See StrictFindAllString for the matches that are rejected.
+func StrictFindString(re *regexp.Regexp, content string) string {
+	if matches := StrictFindAllString(re, content, 1); len(matches) > 0 {
+		return matches[0]
+	}
+	return ""
+}
+
+// StrictFindAllString returns up to n matches of the regular expression in the content that are not
+// embedded within longer tokens, such as hashes or email addresses. Matches are rejected when the
+// surrounding token continues on either side, or when a hash was prefixed to the first label.
+// All matches are returned when n < 0.
+func StrictFindAllString(re *regexp.Regexp, content string, n int) []string {
+	var matches []string
+
+	for _, loc := range re.FindAllStringIndex(content, -1) {
+		if n >= 0 && len(matches) >= n {
+			break
+		}
+		if embeddedMatch(content, loc[0], loc[1]) {
+			continue
+		}
+		matches = append(matches, content[loc[0]:loc[1]])
+	}
+	return matches
+}
+
+// The shortest run of hexadecimal digits considered to be a hash, which is the length of an MD5 digest.
+const minHashLen = 32
+
+// Reports whether the match found at content[start:end] is only part of a longer token.
+func embeddedMatch(content string, start, end int) bool {
+	if start > 0 && tokenByte(content[start-1]) {
+		return true
+	}
+	if end < len(content) && tokenByte(content[end]) {
+		return true
+	}
+
+	label := content[start:end]
+	if i := strings.IndexByte(label, '.'); i >= 0 {
+		label = label[:i]
+	}
+	// A label that is entirely a hash can be a valid name, so only the names appended to one are rejected
+	var hexlen int
+	for hexlen < len(label) && isHexByte(label[hexlen]) {
+		hexlen++
+	}
+	return hexlen >= minHashLen && hexlen < len(label)
+}
+
+func tokenByte(b byte) bool {
+	return b == '@' || b == '-' || b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+func isHexByte(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
 // CopyString return a new string variable with the same value as the parameter.
 func CopyString(src string) string {
 	str := make([]byte, len(src))
@@ -33,6 +33,46 @@ func TestSubdomainRegex(t *testing.T) {
 	}
 }
 
+func TestStrictFindAllString(t *testing.T) {
+	re := AnySubdomainRegex()
+	content := "admin@mail.owasp.org www.owasp.org f00d%2fapi.owasp.org"
+
+	got := StrictFindAllString(re, content, -1)
+	if len(got) != 2 || got[0] != "www.owasp.org" || got[1] != "2fapi.owasp.org" {
+		t.Errorf("StrictFindAllString returned %v", got)
+	}
+	if r := StrictFindString(re, "user@owasp.org"); r != "" {
+		t.Errorf("StrictFindString returned %s for a name following an at sign", r)
+	}
+	if r := StrictFindString(re, "a:www.owasp.org"); r != "www.owasp.org" {
+		t.Errorf("StrictFindString returned %s instead of www.owasp.org", r)
+	}
+
+	tests := []struct {
+		Content  string
+		Expected string
+	}{
+		// The name was appended to a SHA-1 hash
+		{"sha1=2fd4e1c67a2d28fced849ee1bb76e7391b93eb12www.owasp.org", ""},
+		{"2FD4E1C67A2D28FCED849EE1BB76E7391B93EB12api.owasp.org", ""},
+		// A label that is entirely a hash is a valid name
+		{"5d41402abc4b2a76b9719d911017c592.owasp.org", "5d41402abc4b2a76b9719d911017c592.owasp.org"},
+		{"deadbeefwww.owasp.org", "deadbeefwww.owasp.org"},
+		// The token continues after the match
+		{"www.owasp.org9f2c", ""},
+		{"www.owasp.org-abc", ""},
+		{"--www.owasp.org", ""},
+		{"<a href=\"https://www.owasp.org/\">", "www.owasp.org"},
+		{"Visit www.owasp.org.", "www.owasp.org"},
+	}
+
+	for _, test := range tests {
+		if r := StrictFindString(re, test.Content); r != test.Expected {
+			t.Errorf("StrictFindString returned %q for %s instead of %q", r, test.Content, test.Expected)
+		}
+	}
+}
+
 func TestAnySubdomainRegex(t *testing.T) {
 	tests := []struct {
 		Value    string
@@ -0,0 +1,128 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// The URLs of the well-known DNS-over-HTTPS services.
+const (
+	DoHCloudflare = "https://cloudflare-dns.com/dns-query"
+	DoHGoogle     = "https://dns.google/dns-query"
+)
+
+// The longest period of time a DNS-over-HTTPS request is allowed to take.
+const dohTimeout = 10 * time.Second
+
+// The largest DNS message accepted from a DNS-over-HTTPS server.
+const dohMaxMsgSize = 65535
+
+// QueryFunc performs a DNS query using another resolver, such as the resolver pool of the enumeration.
+type QueryFunc func(ctx context.Context, msg *dns.Msg) (*dns.Msg, error)
+
+// DoHClient performs DNS resolutions using a DNS-over-HTTPS (RFC 8484) server.
+type DoHClient struct {
+	url      string
+	client   *http.Client
+	fallback QueryFunc
+}
+
+// NewDoHClient returns a DoHClient that sends queries to the server at the provided URL.
+// The names cloudflare and google select the well-known services.
+func NewDoHClient(url string) *DoHClient {
+	switch strings.ToLower(strings.TrimSpace(url)) {
+	case "cloudflare":
+		url = DoHCloudflare
+	case "google":
+		url = DoHGoogle
+	}
+
+	return &DoHClient{
+		url:    url,
+		client: &http.Client{Timeout: dohTimeout},
+	}
+}
+
+// String returns the URL of the DNS-over-HTTPS server.
+func (c *DoHClient) String() string {
+	return c.url
+}
+
+// SetFallback assigns the function used to answer the query when the DNS-over-HTTPS server fails.
+func (c *DoHClient) SetFallback(f QueryFunc) {
+	c.fallback = f
+}
+
+// Resolve performs a DNS query for the provided name and type, returning the response message
+// in the same form as the resolvers. The fallback is used when the server cannot be reached,
+// returns an HTTP error or responds with a server failure.
+func (c *DoHClient) Resolve(ctx context.Context, name string, qtype uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	msg.RecursionDesired = true
+
+	resp, err := c.exchange(ctx, msg)
+	if err == nil {
+		return resp, nil
+	}
+	if c.fallback == nil || ctx.Err() != nil {
+		return nil, err
+	}
+	return c.fallback(ctx, msg)
+}
+
+func (c *DoHClient) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	// The identifier is zero, so the responses are cacheable by the server (RFC 8484, section 4.1)
+	query := msg.Copy()
+	query.Id = 0
+
+	data, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("The DNS-over-HTTPS server %s returned status code %d", c.url, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, dohMaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(dns.Msg)
+	if err := r.Unpack(body); err != nil {
+		return nil, err
+	}
+	if r.Rcode == dns.RcodeServerFailure {
+		return nil, errors.New("The DNS-over-HTTPS server failed to answer the query")
+	}
+	// Restore the identifier of the original query for the callers
+	r.Id = msg.Id
+	return r, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestDoHClientResolve(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+
+		q := new(dns.Msg)
+		if r.Header.Get("Content-Type") != "application/dns-message" || q.Unpack(data) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		m := new(dns.Msg)
+		m.SetReply(q)
+		m.Answer = append(m.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: q.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   net.ParseIP("192.0.2.1"),
+		})
+
+		out, _ := m.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(out)
+	}))
+	defer ts.Close()
+
+	resp, err := NewDoHClient(ts.URL).Resolve(context.Background(), "www.owasp.org", dns.TypeA)
+	if err != nil {
+		t.Fatalf("Failed to resolve the name: %v", err)
+	}
+	if len(resp.Answer) != 1 {
+		t.Fatalf("Expected one answer, got %d", len(resp.Answer))
+	}
+	if a, ok := resp.Answer[0].(*dns.A); !ok || a.A.String() != "192.0.2.1" {
+		t.Errorf("The answer did not contain the expected address: %v", resp.Answer[0])
+	}
+}
+
+func TestDoHClientFallback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := NewDoHClient(ts.URL)
+	if _, err := c.Resolve(context.Background(), "www.owasp.org", dns.TypeA); err == nil {
+		t.Errorf("Expected an error without a fallback")
+	}
+
+	var called bool
+	c.SetFallback(func(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+		called = true
+
+		m := new(dns.Msg)
+		m.SetReply(msg)
+		return m, nil
+	})
+
+	resp, err := c.Resolve(context.Background(), "www.owasp.org", dns.TypeA)
+	if err != nil || !called {
+		t.Fatalf("The fallback was not used to answer the query: %v", err)
+	}
+	if len(resp.Question) != 1 || resp.Question[0].Name != "www.owasp.org." {
+		t.Errorf("The fallback response did not answer the query")
+	}
+}
+
+func TestNewDoHClientNames(t *testing.T) {
+	if c := NewDoHClient("Cloudflare"); c.String() != DoHCloudflare {
+		t.Errorf("Expected %s, got %s", DoHCloudflare, c.String())
+	}
+	if c := NewDoHClient("google"); c.String() != DoHGoogle {
+		t.Errorf("Expected %s, got %s", DoHGoogle, c.String())
+	}
+}
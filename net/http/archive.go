@@ -0,0 +1,104 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	archiveLock   sync.Mutex
+	archiveDir    string
+	archiveLogger *log.Logger
+	// Keeps the names of the files unique when responses arrive within the same instant
+	archiveSeq uint64
+	// The characters that are not allowed in the names of the archive files
+	unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+)
+
+// SetResponseArchive causes the body of each response read by the package methods to be written to
+// a file within the directory, along with the redacted request URL and the status code. The files are
+// placed in a subdirectory for each data source and named after the domain and the time of the response.
+// An empty directory disables the archive, and write failures are reported to the optional logger.
+func SetResponseArchive(dir string, logger *log.Logger) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("Failed to create the response archive directory %s: %v", dir, err)
+		}
+	}
+
+	archiveLock.Lock()
+	defer archiveLock.Unlock()
+
+	archiveDir = dir
+	archiveLogger = logger
+	return nil
+}
+
+// WithRequestDomain returns a copy of the provided Context carrying the root domain name that the
+// requests made with it are performed for. The name is used for the files of the response archive.
+func WithRequestDomain(ctx context.Context, domain string) context.Context {
+	if domain == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestDomainKey, domain)
+}
+
+// Writes a copy of the response body to the archive, when the archive is enabled.
+func archiveResponse(ctx context.Context, u string, code int, body []byte) {
+	archiveLock.Lock()
+	dir, logger := archiveDir, archiveLogger
+	archiveLock.Unlock()
+
+	if dir == "" {
+		return
+	}
+
+	source := RequestSource(ctx)
+	if source == "" {
+		source = "HTTP"
+	}
+
+	domain, _ := ctx.Value(requestDomainKey).(string)
+	if domain == "" {
+		if parsed, err := url.Parse(u); err == nil {
+			domain = parsed.Hostname()
+		}
+	}
+
+	now := time.Now().UTC()
+	seq := atomic.AddUint64(&archiveSeq, 1)
+	name := fmt.Sprintf("%s_%s_%d.txt", safeFileName(domain), now.Format("20060102T150405.000000000Z"), seq)
+
+	path := filepath.Join(dir, safeFileName(source))
+	err := os.MkdirAll(path, 0755)
+	if err == nil {
+		var buf bytes.Buffer
+
+		fmt.Fprintf(&buf, "URL: %s\nStatus: %d\nDate: %s\n\n", RedactURL(u), code, now.Format(time.RFC3339Nano))
+		buf.Write(body)
+		err = ioutil.WriteFile(filepath.Join(path, name), buf.Bytes(), 0644)
+	}
+	if err != nil && logger != nil {
+		logger.Printf("%s: Failed to archive the response from %s: %v", source, RedactURL(u), err)
+	}
+}
+
+func safeFileName(s string) string {
+	if s = unsafeFileChars.ReplaceAllString(s, "_"); s == "" {
+		return "unknown"
+	}
+	return s
+}
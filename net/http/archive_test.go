@@ -0,0 +1,59 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResponseArchive(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"subdomains":["www.owasp.org"]}`))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := SetResponseArchive(dir, nil); err != nil {
+		t.Fatalf("Failed to enable the response archive: %v", err)
+	}
+	defer func() { _ = SetResponseArchive("", nil) }()
+
+	ctx := WithRequestDomain(WithRequestSource(context.Background(), "Test Source"), "owasp.org")
+	page, err := RequestWebPage(ctx, ts.URL+"/lookup?domain=owasp.org&apikey=secret", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("The request failed: %v", err)
+	}
+	if page != `{"subdomains":["www.owasp.org"]}` {
+		t.Errorf("The archive altered the response body: %s", page)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "Test_Source", "owasp.org_*.txt"))
+	if len(files) != 1 {
+		t.Fatalf("Expected one archived response, got %d", len(files))
+	}
+
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read the archived response: %v", err)
+	}
+	content := string(data)
+	if strings.Contains(content, "secret") {
+		t.Errorf("The archived request URL was not redacted")
+	}
+	if !strings.Contains(content, "Status: 200\n") || !strings.HasSuffix(content, "\n\n"+page) {
+		t.Errorf("The archived response was not written as expected: %s", content)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for the requests of a data source after its circuit breaker opened.
+var ErrCircuitOpen = errors.New("The circuit breaker is open after consecutive request failures")
+
+// The state of the circuit breaker for a single data source.
+type circuit struct {
+	failures int
+	open     bool
+	openedAt time.Time
+	probing  bool
+}
+
+var (
+	breakerLock      sync.Mutex
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	breakerLogger    *log.Logger
+	circuits         = make(map[string]*circuit)
+)
+
+// SetCircuitBreaker causes the requests made on behalf of a data source, identified by the name provided
+// to WithRequestSource, to fail immediately with ErrCircuitOpen once threshold consecutive requests failed.
+// Network errors, 401, 403 and 429 responses, and 5xx responses are counted as failures, and the attempts
+// of a request retried by RequestWithRetry are counted once, after the retries are exhausted. When cooldown
+// is positive, a single request is allowed through after it elapses, and a success closes the circuit.
+// Otherwise, the circuit stays open. A threshold of zero disables the circuit breaker.
+func SetCircuitBreaker(threshold int, cooldown time.Duration, logger *log.Logger) {
+	breakerLock.Lock()
+	defer breakerLock.Unlock()
+
+	if threshold < 0 {
+		threshold = 0
+	}
+	breakerThreshold = threshold
+	breakerCooldown = cooldown
+	breakerLogger = logger
+	circuits = make(map[string]*circuit)
+}
+
+// Returns an error when the circuit of the data source is open, and true when the request
+// was allowed through the open circuit to test whether the data source has recovered.
+func allowRequest(source string) (bool, error) {
+	breakerLock.Lock()
+	defer breakerLock.Unlock()
+
+	if breakerThreshold == 0 || source == "" {
+		return false, nil
+	}
+
+	c, found := circuits[source]
+	if !found || !c.open {
+		return false, nil
+	}
+	// After the cooldown, a single request tests whether the data source has recovered
+	if breakerCooldown > 0 && !c.probing && time.Since(c.openedAt) >= breakerCooldown {
+		c.probing = true
+		return true, nil
+	}
+	return false, fmt.Errorf("%s: %w", source, ErrCircuitOpen)
+}
+
+// Updates the circuit of the data source with the outcome of a request that was sent. When the
+// probe allowed through the open circuit was never sent, another request can test the data source.
+func finishRequest(ctx context.Context, source string, probe, sent bool, code int, err error) {
+	if sent {
+		recordResult(ctx, source, code, err)
+	} else if probe {
+		cancelProbe(source)
+	}
+}
+
+// Updates the circuit of the data source with the outcome of a request.
+func recordResult(ctx context.Context, source string, code int, err error) {
+	// Requests cancelled by the caller say nothing about the health of the data source,
+	// so a cancelled probe allows the next request to test the data source instead
+	if ctx.Err() == context.Canceled {
+		cancelProbe(source)
+		return
+	}
+
+	breakerLock.Lock()
+	defer breakerLock.Unlock()
+
+	if breakerThreshold == 0 || source == "" {
+		return
+	}
+
+	c, found := circuits[source]
+	if !found {
+		c = new(circuit)
+		circuits[source] = c
+	}
+
+	if !requestFailed(code, err) {
+		if c.open && breakerLogger != nil {
+			breakerLogger.Printf("%s: The circuit breaker closed after a successful request", source)
+		}
+		*c = circuit{}
+		return
+	}
+
+	c.failures++
+	if c.probing {
+		c.probing = false
+		c.openedAt = time.Now()
+		return
+	}
+	if !c.open && c.failures >= breakerThreshold {
+		c.open = true
+		c.openedAt = time.Now()
+		if breakerLogger != nil {
+			breakerLogger.Printf("%s: The circuit breaker opened after %d consecutive request failures", source, c.failures)
+		}
+	}
+}
+
+// Allows another request to test the data source when the request allowed by allowRequest was not completed.
+func cancelProbe(source string) {
+	breakerLock.Lock()
+	defer breakerLock.Unlock()
+
+	if c, found := circuits[source]; found {
+		c.probing = false
+	}
+}
+
+func requestFailed(code int, err error) bool {
+	if code == 0 {
+		return err != nil
+	}
+
+	return code == http.StatusUnauthorized || code == http.StatusForbidden ||
+		code == http.StatusTooManyRequests || code >= 500
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var hits, status int32 = 0, http.StatusInternalServerError
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer ts.Close()
+
+	SetCircuitBreaker(2, 100*time.Millisecond, nil)
+	defer SetCircuitBreaker(0, 0, nil)
+
+	ctx := WithRequestSource(context.Background(), "Failing")
+	for i := 0; i < 2; i++ {
+		if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Request %d did not return the server error: %v", i+1, err)
+		}
+	}
+	if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The circuit breaker did not open after the consecutive failures: %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("The open circuit breaker allowed %d requests instead of 2", n)
+	}
+	// Requests for other data sources are not affected
+	if _, err := RequestWebPage(WithRequestSource(context.Background(), "Other"),
+		ts.URL, nil, nil, nil); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The circuit breaker of one data source affected another")
+	}
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	time.Sleep(150 * time.Millisecond)
+	if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); err != nil {
+		t.Errorf("The request after the cooldown failed: %v", err)
+	}
+	if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); err != nil {
+		t.Errorf("The circuit breaker did not close after the successful request: %v", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	SetCircuitBreaker(1, 0, nil)
+	defer SetCircuitBreaker(0, 0, nil)
+
+	ctx := WithRequestSource(context.Background(), "NotFound")
+	for i := 0; i < 3; i++ {
+		if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("The circuit breaker opened for 404 responses")
+		}
+	}
+}
+
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	var status int32 = http.StatusInternalServerError
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer ts.Close()
+
+	SetCircuitBreaker(1, 50*time.Millisecond, nil)
+	defer SetCircuitBreaker(0, 0, nil)
+
+	ctx := WithRequestSource(context.Background(), "Cancelled")
+	if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The first request did not return the server error: %v", err)
+	}
+
+	time.Sleep(75 * time.Millisecond)
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := RequestWebPage(cctx, ts.URL, nil, nil, nil); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The circuit breaker did not allow the probe after the cooldown")
+	}
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); err != nil {
+		t.Errorf("The circuit breaker did not allow another probe after the cancelled one: %v", err)
+	}
+}
+
+func TestCircuitBreakerRequestDelay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	SetCircuitBreaker(1, 0, nil)
+	defer SetCircuitBreaker(0, 0, nil)
+	SetRequestDelay(200*time.Millisecond, 0)
+	defer SetRequestDelay(0, 0)
+
+	ctx := WithRequestSource(context.Background(), "Delayed")
+	if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The first request did not return the server error: %v", err)
+	}
+	// The requests rejected by the open circuit do not wait for the delay between requests
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := RequestWebPage(ctx, ts.URL, nil, nil, nil); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Request %d was not rejected by the open circuit: %v", i+1, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("The rejected requests took %v with a delay of 200ms between requests", elapsed)
+	}
+}
+
+func TestCircuitBreakerCountsRetriedRequestOnce(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	SetRetryPolicy(2, time.Millisecond)
+	defer SetRetryPolicy(2, time.Second)
+	SetCircuitBreaker(2, 0, nil)
+	defer SetCircuitBreaker(0, 0, nil)
+
+	ctx := WithRequestSource(context.Background(), "Retried")
+	// The three attempts of the first request count as a single failure
+	if _, err := RequestWithRetry(ctx, ts.URL, nil, nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The first request did not return the server error: %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("The first request made %d attempts instead of 3", n)
+	}
+	if _, err := RequestWithRetry(ctx, ts.URL, nil, nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The circuit breaker opened after the attempts of a single request: %v", err)
+	}
+	if _, err := RequestWithRetry(ctx, ts.URL, nil, nil, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("The circuit breaker did not open after two failed requests: %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 6 {
+		t.Errorf("The requests made %d attempts instead of 6", n)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Fixture is a recorded response served by FixtureTransport.
+type Fixture struct {
+	StatusCode  int
+	ContentType string
+	Body        string
+}
+
+// FixtureTransport is an http.RoundTripper that serves recorded responses instead of sending
+// requests to the network, so the parsing performed by data sources can be tested offline.
+// Requests for URLs without a fixture receive a 404 Not Found response.
+type FixtureTransport struct {
+	sync.Mutex
+	fixtures map[string]*Fixture
+	requests []string
+}
+
+// NewFixtureTransport returns an initialized FixtureTransport.
+func NewFixtureTransport() *FixtureTransport {
+	return &FixtureTransport{fixtures: make(map[string]*Fixture)}
+}
+
+// Add serves the fixture for requests to the URL argument. When the URL has no query string,
+// the fixture is also served for requests with any query string on the same URL.
+func (t *FixtureTransport) Add(u string, f *Fixture) {
+	t.Lock()
+	defer t.Unlock()
+
+	if f.StatusCode == 0 {
+		f.StatusCode = http.StatusOK
+	}
+	t.fixtures[u] = f
+}
+
+// AddFile serves the content of the file at path for requests to the URL argument.
+// The content type is selected by the file extension, such as .json or .html.
+func (t *FixtureTransport) AddFile(u, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var ctype string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		ctype = "application/json"
+	case ".html", ".htm":
+		ctype = "text/html; charset=utf-8"
+	case ".xml":
+		ctype = "application/xml"
+	default:
+		ctype = "text/plain; charset=utf-8"
+	}
+
+	t.Add(u, &Fixture{ContentType: ctype, Body: string(data)})
+	return nil
+}
+
+// Requests returns the URLs requested through the transport in the order they were received.
+func (t *FixtureTransport) Requests() []string {
+	t.Lock()
+	defer t.Unlock()
+
+	return append([]string(nil), t.requests...)
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	u := req.URL.String()
+	t.Lock()
+	t.requests = append(t.requests, u)
+	f, found := t.fixtures[u]
+	if !found {
+		nq := *req.URL
+		nq.RawQuery = ""
+		f, found = t.fixtures[nq.String()]
+	}
+	t.Unlock()
+
+	if !found {
+		f = &Fixture{
+			StatusCode:  http.StatusNotFound,
+			ContentType: "text/plain; charset=utf-8",
+			Body:        fmt.Sprintf("No fixture was provided for %s", u),
+		}
+	}
+
+	header := make(http.Header)
+	if f.ContentType != "" {
+		header.Set("Content-Type", f.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestFixtureTransport(t *testing.T) {
+	ft := NewFixtureTransport()
+	SetTransport(ft)
+	defer SetTransport(nil)
+
+	ft.Add("https://api.example.com/subdomains", &Fixture{
+		ContentType: "application/json",
+		Body:        `{"subdomains": ["www.example.com"]}`,
+	})
+
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "page.html")
+	if err := ioutil.WriteFile(path, []byte("<a href=\"https://mail.example.com\">"), 0644); err != nil {
+		t.Fatalf("Failed to write the fixture file: %v", err)
+	}
+	if err := ft.AddFile("https://www.example.com/", path); err != nil {
+		t.Fatalf("Failed to add the fixture file: %v", err)
+	}
+
+	ctx := context.Background()
+	page, err := RequestWebPage(ctx, "https://api.example.com/subdomains?apikey=secret", nil, nil, nil)
+	if err != nil || page != `{"subdomains": ["www.example.com"]}` {
+		t.Errorf("The fixture was not served for the request with a query string: %s %v", page, err)
+	}
+
+	if page, err := RequestWebPage(ctx, "https://www.example.com/", nil, nil, nil); err != nil ||
+		page != "<a href=\"https://mail.example.com\">" {
+		t.Errorf("The fixture file was not served: %s %v", page, err)
+	}
+
+	if _, err := RequestWebPage(ctx, "https://unknown.example.com/", nil, nil, nil); err == nil {
+		t.Errorf("The request without a fixture did not return an error")
+	}
+
+	if reqs := ft.Requests(); len(reqs) != 3 || reqs[2] != "https://unknown.example.com/" {
+		t.Errorf("The transport did not record the requests: %v", reqs)
+	}
+}
+
+func TestSetTransportDuringRequests(t *testing.T) {
+	first := NewFixtureTransport()
+	first.Add("https://www.example.com/", &Fixture{Body: "first"})
+	second := NewFixtureTransport()
+	second.Add("https://www.example.com/", &Fixture{Body: "second"})
+	SetTransport(first)
+	defer SetTransport(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				if page, err := RequestWebPage(context.Background(), "https://www.example.com/", nil, nil, nil); err != nil ||
+					(page != "first" && page != "second") {
+					t.Errorf("The request was not served by either transport: %s %v", page, err)
+				}
+			}
+		}()
+	}
+	// The transport is replaced while the requests are being sent
+	for i := 0; i < 20; i++ {
+		if i%2 == 0 {
+			SetTransport(first)
+		} else {
+			SetTransport(second)
+		}
+	}
+	wg.Wait()
+
+	if total := len(first.Requests()) + len(second.Requests()); total != 200 {
+		t.Errorf("The transports received %d of the 200 requests", total)
+	}
+}
+
+func TestWithRequestTransport(t *testing.T) {
+	first, second := NewFixtureTransport(), NewFixtureTransport()
+	first.Add("https://api.example.com/", &Fixture{Body: "first"})
+	second.Add("https://api.example.com/", &Fixture{Body: "second"})
+	// The transport of the package is not used for the requests given their own transport
+	SetTransport(NewFixtureTransport())
+	defer SetTransport(nil)
+
+	for _, ft := range []*FixtureTransport{first, second} {
+		ctx := WithRequestTransport(context.Background(), ft)
+		page, err := RequestWithRetry(ctx, "https://api.example.com/", nil, nil, nil)
+		if err != nil || len(ft.Requests()) != 1 {
+			t.Errorf("The request was not sent with the transport of the Context: %s %v", page, err)
+		}
+	}
+	if page, _ := RequestWebPage(WithRequestTransport(context.Background(), second),
+		"https://api.example.com/", nil, nil, nil); page != "second" {
+		t.Errorf("The response of the other transport was returned: %s", page)
+	}
+}
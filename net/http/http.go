@@ -4,6 +4,11 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -12,6 +17,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
@@ -21,6 +28,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/net/dns"
@@ -29,6 +37,8 @@ import (
 	"github.com/caffix/stringset"
 	"github.com/geziyor/geziyor"
 	"github.com/geziyor/geziyor/client"
+	"github.com/geziyor/geziyor/middleware"
+	"golang.org/x/net/idna"
 )
 
 const (
@@ -41,20 +51,103 @@ const (
 	// AcceptLang is the default HTTP Accept-Language header value used by Amass.
 	AcceptLang = "en-US,en;q=0.8"
 
+	// DefaultMaxResponseSize is the largest response body read by the package methods unless changed.
+	DefaultMaxResponseSize = 100 * 1024 * 1024 // 100MB
+
 	httpTimeout      = 30 * time.Second
 	handshakeTimeout = 5 * time.Second
+	// The longest timeout of a request, which DefaultClient also enforces for the callers using it directly
+	maxHTTPTimeout = 5 * time.Minute
+)
+
+type ctxKey int
+
+const (
+	// The context key for the HTTP request timeout provided by WithRequestTimeout
+	requestTimeoutKey ctxKey = iota
+	// The context key for the data source name provided by WithRequestSource
+	requestSourceKey
+	// The context key for the number of retries provided by WithRequestRetries
+	requestRetriesKey
+	// The context key for the root domain name provided by WithRequestDomain
+	requestDomainKey
+	// The context key for the function provided by WithRequestObserver
+	requestObserverKey
+	// The context key for the RoundTripper provided by WithRequestTransport
+	requestTransportKey
+)
+
+var (
+	retryLock  sync.Mutex
+	maxRetries = 2
+	retryDelay = time.Second
+	// The longest Retry-After delay that RequestWithRetry will wait for
+	maxRetryAfter = time.Minute
+)
+
+var (
+	responseSizeLock sync.RWMutex
+	maxResponseSize  int64 = DefaultMaxResponseSize
+)
+
+var (
+	strictNamesLock sync.RWMutex
+	strictNames     bool
+)
+
+var (
+	singleLabelLock  sync.RWMutex
+	singleLabelNames bool
+	// The last label of a valid name is alphabetic, or a punycode label, since no TLD is numeric
+	tldRE   = regexp.MustCompile(`^([a-zA-Z]{2,63}|[xX][nN]--[a-zA-Z0-9-]{1,59})$`)
+	labelRE = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,63}$`)
+)
+
+var (
+	requestLogLock sync.RWMutex
+	requestLogger  *log.Logger
+)
+
+// The query string parameters with names containing these strings have their values redacted
+var secretParamTokens = []string{"key", "token", "secret", "pass", "auth", "sig", "session"}
+
+var (
+	userAgentsLock sync.Mutex
+	userAgents     []string
+	userAgentIdx   int
 )
 
 var (
 	subRE          = dns.AnySubdomainRegex()
 	crawlRE        = regexp.MustCompile(`\.\w{3,4}($|\?)`)
 	crawlFileTypes = []string{".html", ".htm", "xhtml", ".js", ".php"}
-	nameStripRE    = regexp.MustCompile(`^u[0-9a-f]{4}|20|22|25|2b|2f|3d|3a|40`)
+	absURLRE       = regexp.MustCompile(`https?://[^\s"'<>\x60\\]+`)
+	nameStripLock  sync.RWMutex
+	nameStripRE    = regexp.MustCompile(buildNameStripPattern(defaultNameStripTokens))
 )
 
+// The URL encoding artifacts removed from scraped names by CleanName. The tokens are regular
+// expressions: escaped unicode code points (u002f), whitespace (20), quotes (22), percent
+// signs (25), plus signs (2b), slashes (2f), equal signs (3d), colons (3a) and at signs (40).
+var defaultNameStripTokens = []string{`u[0-9a-f]{4}`, "20", "22", "25", "2b", "2f", "3d", "3a", "40"}
+
+var nameStripTokens = append([]string{}, defaultNameStripTokens...)
+
 // DefaultClient is the same HTTP client used by the package methods.
 var DefaultClient *http.Client
 
+// The transport shared by DefaultClient and the crawler, which SetTransport can replace while requests are sent
+var sharedTransport = new(switchTransport)
+
+// The sizes of the connection pool shared by the data sources, unless SetConnectionPool assigns others.
+// Many data sources send several requests to the same host, so more than the two idle connections
+// per host kept by the net/http default are retained for reuse.
+const (
+	defaultMaxIdleConns        = 200
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
 // BasicAuth contains the data used for HTTP basic authentication.
 type BasicAuth struct {
 	Username string
@@ -63,20 +156,203 @@ type BasicAuth struct {
 
 func init() {
 	jar, _ := cookiejar.New(nil)
+	defaultTransport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           amassnet.DialContext,
+		MaxIdleConns:          defaultMaxIdleConns,
+		MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
+		MaxConnsPerHost:       50,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   handshakeTimeout,
+		ExpectContinueTimeout: 10 * time.Second,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+	}
+	// The request timeout is enforced through the context by requestWebPage, so the
+	// client timeout is only the ceiling for the requests given longer timeouts
 	DefaultClient = &http.Client{
-		Timeout: httpTimeout,
-		Transport: &http.Transport{
-			Proxy:                 http.ProxyFromEnvironment,
-			DialContext:           amassnet.DialContext,
-			MaxIdleConns:          200,
-			MaxConnsPerHost:       50,
-			IdleConnTimeout:       90 * time.Second,
-			TLSHandshakeTimeout:   handshakeTimeout,
-			ExpectContinueTimeout: 10 * time.Second,
-			TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
-		},
-		Jar: jar,
+		Timeout:   maxHTTPTimeout,
+		Transport: &decompressTransport{transport: sharedTransport},
+		Jar:       jar,
 	}
+	sharedTransport.setNetwork(defaultTransport)
+}
+
+// SetTransport causes the package methods and the web crawler to send requests with the provided
+// RoundTripper, such as a FixtureTransport serving recorded responses, instead of the network.
+// A nil argument restores the default transport. The requests already in flight complete with
+// the previous transport. The transport is shared by the whole process, so WithRequestTransport
+// is used to provide the transport of the requests made for a single System.
+func SetTransport(rt http.RoundTripper) {
+	sharedTransport.set(rt)
+}
+
+// switchTransport sends the requests with the RoundTripper most recently provided by set.
+type switchTransport struct {
+	sync.RWMutex
+	transport http.RoundTripper
+	// The transport that sends the requests to the network, which replaces a nil RoundTripper
+	network *http.Transport
+}
+
+func (t *switchTransport) get() http.RoundTripper {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.transport
+}
+
+func (t *switchTransport) set(rt http.RoundTripper) {
+	t.Lock()
+	defer t.Unlock()
+
+	if rt == nil {
+		rt = t.network
+	}
+	t.transport = rt
+}
+
+func (t *switchTransport) setNetwork(nt *http.Transport) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.network = nt
+	t.transport = nt
+}
+
+// Replaces the network transport with a copy changed by the function provided, since the fields of a
+// transport cannot be changed while it sends requests. The requests in flight complete with the previous
+// transport. When SetTransport provided another RoundTripper, the copy is used once the default is restored.
+func (t *switchTransport) updateNetwork(change func(*http.Transport)) {
+	t.Lock()
+	defer t.Unlock()
+
+	old := t.network
+	t.network = old.Clone()
+	change(t.network)
+	if t.transport == http.RoundTripper(old) {
+		t.transport = t.network
+	}
+	old.CloseIdleConnections()
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *switchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt, ok := req.Context().Value(requestTransportKey).(http.RoundTripper); ok {
+		return rt.RoundTrip(req)
+	}
+	return t.get().RoundTrip(req)
+}
+
+// SetConnectionPool assigns the number of idle connections kept open for reuse by the default transport,
+// in total and for each host, and the period an idle connection is kept open. Zero values select the
+// defaults. The requests already in flight complete with the previous settings.
+func SetConnectionPool(maxIdle, maxIdlePerHost int, idleTimeout time.Duration) {
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	if maxIdlePerHost <= 0 {
+		maxIdlePerHost = defaultMaxIdleConnsPerHost
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleConnTimeout
+	}
+
+	sharedTransport.updateNetwork(func(t *http.Transport) {
+		t.MaxIdleConns = maxIdle
+		t.MaxIdleConnsPerHost = maxIdlePerHost
+		t.IdleConnTimeout = idleTimeout
+	})
+}
+
+// SetStrictNameMatching determines whether CleanName and the crawler reject the DNS names that are
+// embedded within longer tokens, such as hashes or the local part of email addresses.
+func SetStrictNameMatching(strict bool) {
+	strictNamesLock.Lock()
+	defer strictNamesLock.Unlock()
+
+	strictNames = strict
+}
+
+// SetAllowSingleLabelNames determines whether CleanName accepts names without a dot and TLD, such as
+// the hostnames of internal networks. By default, those names are rejected.
+func SetAllowSingleLabelNames(allow bool) {
+	singleLabelLock.Lock()
+	defer singleLabelLock.Unlock()
+
+	singleLabelNames = allow
+}
+
+func allowSingleLabel() bool {
+	singleLabelLock.RLock()
+	defer singleLabelLock.RUnlock()
+
+	return singleLabelNames
+}
+
+// Returns the DNS names found in the content, honoring the strict name matching setting.
+func findNames(content string, n int) []string {
+	strictNamesLock.RLock()
+	strict := strictNames
+	strictNamesLock.RUnlock()
+
+	if strict {
+		return dns.StrictFindAllString(subRE, content, n)
+	}
+	return subRE.FindAllString(content, n)
+}
+
+// SetUserAgents sets the User-Agent strings that are rotated through by the web requests and
+// the crawler. An empty list restores the use of the UserAgent constant.
+func SetUserAgents(agents []string) {
+	userAgentsLock.Lock()
+	defer userAgentsLock.Unlock()
+
+	userAgents = nil
+	for _, agent := range agents {
+		if a := strings.TrimSpace(agent); a != "" {
+			userAgents = append(userAgents, a)
+		}
+	}
+	userAgentIdx = 0
+}
+
+// NextUserAgent returns the User-Agent string for the next web request. The strings provided by
+// SetUserAgents are selected in round-robin order, and the UserAgent constant is used otherwise.
+func NextUserAgent() string {
+	userAgentsLock.Lock()
+	defer userAgentsLock.Unlock()
+
+	if len(userAgents) == 0 {
+		return UserAgent
+	}
+
+	agent := userAgents[userAgentIdx]
+	userAgentIdx = (userAgentIdx + 1) % len(userAgents)
+	return agent
+}
+
+// SetProxy causes the HTTP client and web crawler to send requests through the proxy at the URL argument.
+func SetProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+		return fmt.Errorf("The proxy URL %s is not valid", proxy)
+	}
+
+	sharedTransport.updateNetwork(func(t *http.Transport) {
+		t.Proxy = http.ProxyURL(u)
+	})
+	return nil
+}
+
+// ResetProxy removes the proxy assigned by SetProxy, so the requests use the proxy
+// selected by the environment variables again.
+func ResetProxy() {
+	sharedTransport.updateNetwork(func(t *http.Transport) {
+		t.Proxy = http.ProxyFromEnvironment
+	})
 }
 
 // CopyCookies copies cookies from one domain to another. Some of our data
@@ -101,20 +377,374 @@ func CheckCookie(urlString string, cookieName string) bool {
 	return found
 }
 
+// WithRequestTimeout returns a copy of the provided Context carrying a timeout that takes the
+// place of the default timeout for each request made with it by the package methods.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestTimeoutKey, timeout)
+}
+
+// RequestTimeout returns the timeout applied to requests made with the provided Context,
+// which cannot exceed the timeout of DefaultClient.
+func RequestTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(requestTimeoutKey).(time.Duration); ok && d > 0 {
+		if d > maxHTTPTimeout {
+			d = maxHTTPTimeout
+		}
+		return d
+	}
+	return httpTimeout
+}
+
+// WithRequestRetries returns a copy of the provided Context carrying the number of times that
+// RequestWithRetry retries each request made with it, in place of the package retry policy.
+// Zero turns the retries off, and a negative number keeps the package retry policy.
+func WithRequestRetries(ctx context.Context, retries int) context.Context {
+	if retries < 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestRetriesKey, retries)
+}
+
+// WithRequestSource returns a copy of the provided Context carrying the name of the data source
+// that the requests made with it are performed for. The name is included in the request logs.
+func WithRequestSource(ctx context.Context, source string) context.Context {
+	if source == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestSourceKey, source)
+}
+
+// WithRequestTransport returns a copy of the provided Context carrying the RoundTripper that sends the
+// requests made with it by the package methods and the web crawler, in place of the transport provided
+// to SetTransport. This allows each System to send its requests with its own transport, such as a
+// FixtureTransport serving recorded responses. A nil RoundTripper keeps the transport of the package.
+func WithRequestTransport(ctx context.Context, rt http.RoundTripper) context.Context {
+	if rt == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestTransportKey, rt)
+}
+
+// WithRequestObserver returns a copy of the provided Context carrying a function that is called with the
+// result of each request made with it by RequestWebPage and RequestWithRetry, such as for the metrics of a
+// data source. The function is called once for each request, after any retries have been attempted.
+func WithRequestObserver(ctx context.Context, observe func(err error)) context.Context {
+	if observe == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestObserverKey, observe)
+}
+
+func observeRequest(ctx context.Context, err error) {
+	if observe, ok := ctx.Value(requestObserverKey).(func(error)); ok {
+		observe(err)
+	}
+}
+
+// RequestSource returns the data source name carried by the provided Context.
+func RequestSource(ctx context.Context) string {
+	if s, ok := ctx.Value(requestSourceKey).(string); ok {
+		return s
+	}
+	return ""
+}
+
+// SetRequestLogger causes the method, URL and status code of every request made by the package
+// methods to be written to the provided Logger. Secrets within the URLs are redacted, and a nil
+// Logger disables the request logging.
+func SetRequestLogger(logger *log.Logger) {
+	requestLogLock.Lock()
+	defer requestLogLock.Unlock()
+
+	requestLogger = logger
+}
+
+func logRequest(ctx context.Context, method, u string, code int, err error) {
+	requestLogLock.RLock()
+	logger := requestLogger
+	requestLogLock.RUnlock()
+
+	if logger == nil {
+		return
+	}
+
+	source := RequestSource(ctx)
+	if source == "" {
+		source = "HTTP"
+	}
+
+	status := strconv.Itoa(code)
+	if code == 0 && err != nil {
+		status = err.Error()
+	}
+	logger.Printf("%s: %s %s %s", source, method, RedactURL(u), status)
+}
+
+// RedactURL returns the URL with the password of the user information and the values of query
+// string parameters that appear to hold secrets, such as API keys and tokens, replaced.
+func RedactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+
+	if parsed.User != nil {
+		if _, set := parsed.User.Password(); set {
+			parsed.User = url.UserPassword(parsed.User.Username(), "REDACTED")
+		}
+	}
+
+	if parsed.RawQuery != "" {
+		params := strings.Split(parsed.RawQuery, "&")
+
+		for i, param := range params {
+			parts := strings.SplitN(param, "=", 2)
+			if len(parts) != 2 || parts[1] == "" {
+				continue
+			}
+
+			key := strings.ToLower(parts[0])
+			if k, err := url.QueryUnescape(key); err == nil {
+				key = k
+			}
+			for _, token := range secretParamTokens {
+				if strings.Contains(key, token) {
+					params[i] = parts[0] + "=REDACTED"
+					break
+				}
+			}
+		}
+		parsed.RawQuery = strings.Join(params, "&")
+	}
+
+	return parsed.String()
+}
+
 // RequestWebPage returns a string containing the entire response for the provided URL when successful.
 func RequestWebPage(ctx context.Context, u string, body io.Reader, hvals map[string]string, auth *BasicAuth) (string, error) {
+	page, _, _, err := requestWebPage(ctx, u, body, hvals, auth)
+
+	observeRequest(ctx, err)
+	return page, err
+}
+
+// SetMaxResponseSize sets the largest number of bytes read from a response body by the package
+// methods. Larger responses cause an error to be returned, and a value of zero restores the default.
+func SetMaxResponseSize(max int64) {
+	responseSizeLock.Lock()
+	defer responseSizeLock.Unlock()
+
+	if max <= 0 {
+		max = DefaultMaxResponseSize
+	}
+	maxResponseSize = max
+}
+
+func maxBodySize() int64 {
+	responseSizeLock.RLock()
+	defer responseSizeLock.RUnlock()
+
+	return maxResponseSize
+}
+
+// SetRetryPolicy sets the number of times RequestWithRetry will retry a failed request
+// and the base delay used for the exponential backoff between the attempts.
+func SetRetryPolicy(retries int, delay time.Duration) {
+	retryLock.Lock()
+	defer retryLock.Unlock()
+
+	if retries < 0 {
+		retries = 0
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	maxRetries = retries
+	retryDelay = delay
+}
+
+// SetMaxRetryAfter sets the longest delay requested by a Retry-After header that RequestWithRetry
+// will wait before retrying. Longer delays cause the error to be returned immediately.
+func SetMaxRetryAfter(max time.Duration) {
+	retryLock.Lock()
+	defer retryLock.Unlock()
+
+	if max < 0 {
+		max = 0
+	}
+	maxRetryAfter = max
+}
+
+// RequestWithRetry performs the same request as RequestWebPage, but retries network failures and
+// 5xx responses using exponential backoff with jitter. Responses with 4xx status codes are not retried,
+// except for a single retry of a 429 response that provides a Retry-After header. The Retry-After delay
+// of 429 and 503 responses is honored up to the limit set by SetMaxRetryAfter. Requests with a body are
+// sent as POST requests, which are not idempotent, so they are only retried after a Retry-After delay.
+func RequestWithRetry(ctx context.Context, u string, body io.Reader, hvals map[string]string, auth *BasicAuth) (string, error) {
+	page, err := requestWithRetry(ctx, u, body, hvals, auth)
+
+	observeRequest(ctx, err)
+	return page, err
+}
+
+func requestWithRetry(ctx context.Context, u string, body io.Reader, hvals map[string]string, auth *BasicAuth) (string, error) {
+	var data []byte
+	if body != nil {
+		var err error
+
+		data, err = ioutil.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	retryLock.Lock()
+	retries, delay, maxWait := maxRetries, retryDelay, maxRetryAfter
+	retryLock.Unlock()
+	if n, ok := ctx.Value(requestRetriesKey).(int); ok && n >= 0 {
+		retries = n
+	}
+
+	source := RequestSource(ctx)
+	var probe, sent bool
+	var code int
+	page, err := func() (string, error) {
+		var waited bool
+		for attempt := 0; ; attempt++ {
+			// A probe allowed through the open circuit keeps its retries, while the
+			// other requests stop as soon as the circuit of the data source opens
+			if attempt == 0 || !probe {
+				p, err := allowRequest(source)
+				if err != nil {
+					return "", err
+				}
+				probe = p
+			}
+
+			var b io.Reader
+			if body != nil {
+				b = bytes.NewReader(data)
+			}
+
+			page, c, hdr, s, err := sendRequest(ctx, u, b, hvals, auth)
+			if s {
+				sent, code = true, c
+			}
+			if err == nil || ctx.Err() != nil {
+				return page, err
+			}
+
+			// The server asked for a pause, so honor it once without using up an attempt
+			if wait, ok := retryAfterDelay(c, hdr); ok && !waited {
+				if wait > maxWait {
+					return page, fmt.Errorf("%v: the Retry-After delay of %v exceeds the maximum of %v", err, wait, maxWait)
+				}
+
+				waited = true
+				if !sleepContext(ctx, wait) {
+					return page, err
+				}
+				attempt--
+				continue
+			}
+
+			if body != nil || attempt >= retries || (c >= 400 && c < 500) {
+				return page, err
+			}
+
+			backoff := delay << uint(attempt)
+			if delay > 0 {
+				backoff += time.Duration(rand.Int63n(int64(delay)))
+			}
+
+			if !sleepContext(ctx, backoff) {
+				return page, err
+			}
+		}
+	}()
+	// The circuit breaker is updated once the retries are exhausted, so a failed request counts as a single failure
+	finishRequest(ctx, source, probe, sent, code, err)
+	return page, err
+}
+
+// Returns the delay requested by the Retry-After header of 429 and 503 responses.
+func retryAfterDelay(code int, hdr http.Header) (time.Duration, bool) {
+	if hdr == nil || (code != http.StatusTooManyRequests && code != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	v := strings.TrimSpace(hdr.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	// The value can be a number of seconds or an HTTP date
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			secs = 0
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if wait := time.Until(t); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Waits for the duration and returns false if the context was done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+	}
+	return true
+}
+
+func requestWebPage(ctx context.Context, u string, body io.Reader, hvals map[string]string, auth *BasicAuth) (string, int, http.Header, error) {
+	// The requests rejected by the circuit breaker do not take a turn from the global delay between requests
+	source := RequestSource(ctx)
+	probe, err := allowRequest(source)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	page, code, hdr, sent, err := sendRequest(ctx, u, body, hvals, auth)
+	finishRequest(ctx, source, probe, sent, code, err)
+	return page, code, hdr, err
+}
+
+// Sends a single request without consulting the circuit breaker, and returns whether the request
+// was sent, since the requests that were never sent say nothing about the health of the data source.
+func sendRequest(ctx context.Context, u string, body io.Reader, hvals map[string]string, auth *BasicAuth) (string, int, http.Header, bool, error) {
 	method := "GET"
 	if body != nil {
 		method = "POST"
 	}
+	// The global delay between requests is not included in the timeout of this request
+	if !waitRequestDelay(ctx) {
+		return "", 0, nil, false, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout(ctx))
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
-		return "", err
+		return "", 0, nil, false, err
 	}
 	if auth != nil && auth.Username != "" && auth.Password != "" {
 		req.SetBasicAuth(auth.Username, auth.Password)
 	}
-	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("User-Agent", NextUserAgent())
 	req.Header.Set("Accept", Accept)
 	req.Header.Set("Accept-Language", AcceptLang)
 
@@ -124,20 +754,129 @@ func RequestWebPage(ctx context.Context, u string, body io.Reader, hvals map[str
 
 	resp, err := DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		logRequest(ctx, method, u, 0, err)
+		return "", 0, nil, true, err
 	}
+	logRequest(ctx, method, resp.Request.URL.String(), resp.StatusCode, nil)
 
-	in, err := ioutil.ReadAll(resp.Body)
+	// Limit the memory consumed by exceptionally large responses. Reading one byte past
+	// the limit shows whether the body was larger, instead of returning a truncated page
+	max := maxBodySize()
+	in, err := ioutil.ReadAll(io.LimitReader(resp.Body, max+1))
 	resp.Body.Close()
+	if err == nil && int64(len(in)) > max {
+		return "", resp.StatusCode, resp.Header, true, fmt.Errorf("The response from %s exceeded the maximum size of %d bytes", RedactURL(u), max)
+	}
+	if err == nil {
+		archiveResponse(ctx, u, resp.StatusCode, in)
+	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
 		err = errors.New(resp.Status)
 	}
-	return string(in), err
+	return string(in), resp.StatusCode, resp.Header, true, err
+}
+
+// CrawlOptions contains the settings that tune the behavior of the web crawler.
+type CrawlOptions struct {
+	// The maximum number of links followed by the crawler, unless the value is zero
+	MaxLinks int
+
+	// The maximum number of hops from the starting URL, unless the value is zero
+	MaxDepth int
+
+	// The budget of requests for the entire crawl, which keeps link-heavy sites from monopolizing
+	// the crawler. New URLs are not followed once it's reached, unless the value is zero or negative
+	MaxRequests int
+
+	// The amount of time allowed for the entire crawl
+	Timeout time.Duration
+
+	// The maximum number of concurrent requests made by the crawler
+	ConcurrentRequests int
+
+	// The user agent presented by the crawler. When empty, each request presents
+	// the next user agent returned by NextUserAgent
+	UserAgent string
+
+	// When set to true, the crawler fetches and honors the robots.txt files of the crawled sites.
+	// Paths disallowed by the sites are skipped, which can reduce the names discovered
+	HonorRobotsTxt bool
+
+	// The delay between requests, which is randomized when RequestDelayRandomize is true
+	RequestDelay          time.Duration
+	RequestDelayRandomize bool
+}
+
+// DefaultCrawlOptions returns the settings used by the crawler when no options are provided.
+func DefaultCrawlOptions() *CrawlOptions {
+	return &CrawlOptions{
+		Timeout:               5 * time.Minute,
+		ConcurrentRequests:    5,
+		RequestDelay:          750 * time.Millisecond,
+		RequestDelayRandomize: true,
+	}
+}
+
+// NewCrawlOptions returns the default crawl options changed by the provided settings.
+// The timeout, request delay, number of concurrent requests and request budget keep
+// their defaults when the values are zero or negative.
+func NewCrawlOptions(timeout, delay time.Duration, concurrent, maxRequests int, honorRobotsTxt bool) *CrawlOptions {
+	opts := DefaultCrawlOptions()
+
+	opts.HonorRobotsTxt = honorRobotsTxt
+	if timeout > 0 {
+		opts.Timeout = timeout
+	}
+	if delay > 0 {
+		opts.RequestDelay = delay
+	}
+	if concurrent > 0 {
+		opts.ConcurrentRequests = concurrent
+	}
+	if maxRequests > 0 {
+		opts.MaxRequests = maxRequests
+	}
+	return opts
+}
+
+// userAgentRotation presents the next user agent on each request made by the crawler.
+type userAgentRotation struct{}
+
+func (userAgentRotation) ProcessRequest(r *client.Request) {
+	r.Header.Set("User-Agent", NextUserAgent())
 }
 
 // Crawl will spider the web page at the URL argument looking for DNS names within the scope argument.
+// The default crawl options are used, and no more than max links are followed when max is not zero.
 func Crawl(ctx context.Context, u string, scope []string, max int, filter stringfilter.Filter) ([]string, error) {
+	opts := DefaultCrawlOptions()
+	opts.MaxLinks = max
+
+	return CrawlWithOptions(ctx, u, scope, filter, opts)
+}
+
+// CrawlWithOptions will spider the web page at the URL argument looking for DNS names within the
+// scope argument, using the provided crawl options. The default options are used when opts is nil.
+func CrawlWithOptions(ctx context.Context, u string, scope []string, filter stringfilter.Filter, opts *CrawlOptions) ([]string, error) {
+	defs := DefaultCrawlOptions()
+	if opts == nil {
+		opts = defs
+	} else {
+		o := *opts
+		opts = &o
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defs.Timeout
+	}
+	if opts.ConcurrentRequests <= 0 {
+		opts.ConcurrentRequests = defs.ConcurrentRequests
+	}
+	// The crawler cannot randomize a delay of zero
+	if opts.RequestDelay <= 0 {
+		opts.RequestDelayRandomize = false
+	}
+
 	newScope := append([]string{}, scope...)
 
 	target := subRE.FindString(u)
@@ -158,21 +897,36 @@ func Crawl(ctx context.Context, u string, scope []string, max int, filter string
 		filter = stringfilter.NewStringFilter()
 	}
 
+	var middlewares []middleware.RequestProcessor
+	ua := opts.UserAgent
+	if ua == "" {
+		ua = UserAgent
+		middlewares = append(middlewares, userAgentRotation{})
+	}
+
 	var count int
 	var m sync.Mutex
 	results := stringset.New()
 	g := geziyor.NewGeziyor(&geziyor.Options{
 		AllowedDomains:        newScope,
 		StartURLs:             []string{u},
-		Timeout:               5 * time.Minute,
-		RobotsTxtDisabled:     true,
-		UserAgent:             UserAgent,
+		Timeout:               opts.Timeout,
+		RobotsTxtDisabled:     !opts.HonorRobotsTxt,
+		UserAgent:             ua,
+		RequestMiddlewares:    middlewares,
 		LogDisabled:           true,
-		ConcurrentRequests:    5,
-		RequestDelay:          750 * time.Millisecond,
-		RequestDelayRandomize: true,
+		ConcurrentRequests:    opts.ConcurrentRequests,
+		RequestDelay:          opts.RequestDelay,
+		RequestDelayRandomize: opts.RequestDelayRandomize,
 		ParseFunc: func(g *geziyor.Geziyor, r *client.Response) {
-			for _, n := range subRE.FindAllString(string(r.Body), -1) {
+			// Stop following links once the context has been canceled
+			if ctx.Err() != nil {
+				return
+			}
+			cur, _ := r.Request.Meta["depth"].(int)
+
+			// The entire body is searched, which includes inline scripts, attributes and JSON
+			for _, n := range findNames(string(r.Body), -1) {
 				if name := CleanName(n); whichDomain(name, scope) != "" {
 					m.Lock()
 					results.Insert(name)
@@ -207,6 +961,11 @@ func Crawl(ctx context.Context, u string, scope []string, max int, filter string
 							return
 						}
 					}
+					// Be sure the crawl has not exceeded the maximum depth before the URL is marked
+					// as seen, since it can still be followed when found on a shallower page
+					if opts.MaxDepth > 0 && cur >= opts.MaxDepth {
+						return
+					}
 					// Remove fragments and check if we've seen this URL before
 					p.Fragment = ""
 					p.RawFragment = ""
@@ -218,12 +977,35 @@ func Crawl(ctx context.Context, u string, scope []string, max int, filter string
 					count++
 					current := count
 					m.Unlock()
-					if max <= 0 || current < max {
-						g.Get(p.String(), g.Opt.ParseFunc)
+					// The starting URL is included in the request budget
+					if opts.MaxRequests > 0 && current >= opts.MaxRequests {
+						return
+					}
+					if opts.MaxLinks <= 0 || current < opts.MaxLinks {
+						req, err := client.NewRequest("GET", p.String(), nil)
+						if err != nil {
+							return
+						}
+
+						req.Meta["depth"] = cur + 1
+						g.Do(req, g.Opt.ParseFunc)
 					}
 				}
 			}
 
+			// Follow absolute URLs found in scripts, JSON and attributes other than anchors. Only the
+			// hosts within the scope are followed, since the pages reference many unrelated hosts,
+			// such as those of analytics and CDN services, and the start host is reached by anchors
+			for _, link := range absURLRE.FindAllString(string(r.Body), -1) {
+				if p, err := url.Parse(link); err == nil && whichDomain(p.Hostname(), scope) != "" {
+					processURL(link)
+				}
+			}
+			// Only HTML responses have a document to traverse
+			if r.HTMLDoc == nil {
+				return
+			}
+
 			r.HTMLDoc.Find("a").Each(func(i int, s *goquery.Selection) {
 				if href, ok := s.Attr("href"); ok {
 					processURL(r.JoinURL(href))
@@ -238,12 +1020,18 @@ func Crawl(ctx context.Context, u string, scope []string, max int, filter string
 		},
 	})
 	options := &client.Options{
-		MaxBodySize:    100 * 1024 * 1024, // 100MB
+		MaxBodySize:    maxBodySize(),
 		RetryTimes:     2,
 		RetryHTTPCodes: []int{408, 500, 502, 503, 504, 522, 524},
 	}
 	g.Client = client.NewClient(options)
-	g.Client.Client = http.DefaultClient
+	// Share the transport so the crawler honors the proxy settings
+	g.Client.Client = &http.Client{
+		Transport: &contextTransport{
+			ctx:       ctx,
+			transport: DefaultClient.Transport,
+		},
+	}
 
 	done := make(chan struct{}, 2)
 	go func() {
@@ -254,16 +1042,109 @@ func Crawl(ctx context.Context, u string, scope []string, max int, filter string
 	var err error
 	select {
 	case <-ctx.Done():
-		err = fmt.Errorf("The context expired during the crawl of %s", u)
+		// The names collected before the cancellation are still returned
+		err = ctx.Err()
 	case <-done:
-		if len(results.Slice()) == 0 {
-			err = fmt.Errorf("No DNS names were discovered during the crawl of %s", u)
-		}
 	}
 
+	m.Lock()
+	defer m.Unlock()
+
+	if err == nil && results.Len() == 0 {
+		err = fmt.Errorf("No DNS names were discovered during the crawl of %s", u)
+	}
 	return results.Slice(), err
 }
 
+// contextTransport causes the requests sent by the crawler to be canceled with the context.
+type contextTransport struct {
+	ctx       context.Context
+	transport http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(req.WithContext(t.ctx))
+}
+
+// decompressTransport decodes gzip and deflate response bodies that were not already
+// decompressed by the underlying transport, such as those sent without being requested.
+type decompressTransport struct {
+	transport http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *decompressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.Uncompressed || req.Method == "HEAD" {
+		return resp, err
+	}
+
+	var body io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		body, err = newGzipBody(resp.Body)
+	case "deflate":
+		body, err = newDeflateBody(resp.Body)
+	default:
+		return resp, nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Prevent the body from being decompressed again by the callers
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// decodedBody closes the original response body along with the decompressing reader.
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close implements the io.Closer interface.
+func (d *decodedBody) Close() error {
+	var err error
+
+	for _, c := range d.closers {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func newGzipBody(body io.ReadCloser) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+	return &decodedBody{Reader: zr, closers: []io.Closer{zr, body}}, nil
+}
+
+// Servers send the deflate encoding both with and without the zlib wrapper.
+func newDeflateBody(body io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+
+	if h, err := br.Peek(2); err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedBody{Reader: zr, closers: []io.Closer{zr, body}}, nil
+	}
+
+	fr := flate.NewReader(br)
+	return &decodedBody{Reader: fr, closers: []io.Closer{fr, body}}, nil
+}
+
 func whichDomain(name string, scope []string) string {
 	n := strings.TrimSpace(name)
 
@@ -374,24 +1255,147 @@ func ClientCountryCode(ctx context.Context) string {
 }
 
 // CleanName will clean up the names scraped from the web.
+// Internationalized domain names are converted into the punycode form.
 func CleanName(name string) string {
+	return cleanName(name, false)
+}
+
+// CleanNamePreserveCase performs the same clean up as CleanName without lowercasing the name.
+func CleanNamePreserveCase(name string) string {
+	return cleanName(name, true)
+}
+
+// CleanWildcardName performs the same clean up as CleanName on names that can begin with a wildcard
+// label, such as those found in certificates. When keepWildcard is true, the returned name keeps the
+// "*." prefix, and the name without the wildcard is returned otherwise.
+func CleanWildcardName(name string, keepWildcard bool) string {
+	name, wildcard := trimWildcard(strings.TrimSpace(name))
+
+	name = cleanName(name, false)
+	if wildcard && keepWildcard && name != "" {
+		return "*." + name
+	}
+	return name
+}
+
+func cleanName(name string, preserveCase bool) string {
 	var err error
 
-	name, err = strconv.Unquote("\"" + strings.TrimSpace(name) + "\"")
+	// Wildcard labels are removed as a whole, instead of leaving the dot behind
+	name, _ = trimWildcard(strings.TrimSpace(name))
+	name, err = strconv.Unquote("\"" + name + "\"")
 	if err == nil {
-		name = subRE.FindString(name)
+		var match string
+		if names := findNames(toASCII(name), 1); len(names) > 0 {
+			match = names[0]
+		} else if allowSingleLabel() && labelRE.MatchString(strings.Trim(name, ".")) {
+			// The name pattern requires a TLD, so single label names are only matched here
+			match = name
+		}
+		name = match
 	}
 
-	name = strings.ToLower(name)
+	if !preserveCase {
+		name = strings.ToLower(name)
+	}
 	for {
 		name = strings.Trim(name, "-.")
 
-		if i := nameStripRE.FindStringIndex(name); i != nil {
+		nameStripLock.RLock()
+		i := nameStripRE.FindStringIndex(name)
+		nameStripLock.RUnlock()
+
+		if i != nil {
 			name = name[i[1]:]
 		} else {
 			break
 		}
 	}
 
+	if !validCleanName(name) {
+		return ""
+	}
+	return name
+}
+
+// Checks that the cleaned name has at least two labels and a suffix that looks like a TLD,
+// unless single label names have been allowed.
+func validCleanName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	labels := strings.Split(name, ".")
+	if len(labels) == 1 {
+		return allowSingleLabel()
+	}
+	return tldRE.MatchString(labels[len(labels)-1])
+}
+
+// AddNameStripTokens extends the URL encoding artifacts removed from scraped names by CleanName.
+// Each token is a regular expression, such as "3b" for an encoded semicolon.
+func AddNameStripTokens(tokens ...string) error {
+	nameStripLock.Lock()
+	defer nameStripLock.Unlock()
+
+	return setNameStripTokens(append(append([]string{}, nameStripTokens...), tokens...))
+}
+
+// The caller must hold the nameStripLock.
+func setNameStripTokens(tokens []string) error {
+	re, err := regexp.Compile(buildNameStripPattern(tokens))
+	if err != nil {
+		return err
+	}
+
+	nameStripTokens = tokens
+	nameStripRE = re
+	return nil
+}
+
+// The default tokens keep the original strip pattern, where only the first token is anchored to the
+// start of the name. Each added token is anchored, so it is not removed from the middle of a name.
+func buildNameStripPattern(tokens []string) string {
+	n := len(defaultNameStripTokens)
+	if len(tokens) < n {
+		n = len(tokens)
+	}
+
+	pattern := "(?i)^" + strings.Join(tokens[:n], "|")
+	for _, token := range tokens[n:] {
+		pattern += "|^(?:" + token + ")"
+	}
+	return pattern
+}
+
+// Removes the leading wildcard labels from the name and reports whether any were found.
+func trimWildcard(name string) (string, bool) {
+	var found bool
+
+	for strings.HasPrefix(name, "*.") {
+		name = name[2:]
+		found = true
+	}
+	if name == "*" {
+		return "", true
+	}
+	return name, found
+}
+
+func toASCII(name string) string {
+	if strings.Contains(name, "%") {
+		if n, err := url.PathUnescape(name); err == nil {
+			name = n
+		}
+	}
+
+	for _, r := range name {
+		if r > unicode.MaxASCII {
+			if n, err := idna.Lookup.ToASCII(name); err == nil {
+				return n
+			}
+			break
+		}
+	}
 	return name
 }
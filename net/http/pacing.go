@@ -0,0 +1,66 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	pacingLock   sync.Mutex
+	pacingDelay  time.Duration
+	pacingJitter time.Duration
+	// The earliest time that the next web request can be sent
+	nextRequest time.Time
+)
+
+// SetRequestDelay assigns the minimum delay between the web requests sent by the package methods on behalf
+// of all the data sources, along with the largest random duration added to each delay. Unlike the rate
+// limits of the data sources, this spreads the requests of every data source over time. A delay of zero,
+// the default, sends the requests as soon as the data sources are permitted to.
+func SetRequestDelay(delay, jitter time.Duration) {
+	pacingLock.Lock()
+	defer pacingLock.Unlock()
+
+	if delay < 0 {
+		delay = 0
+	}
+	if jitter < 0 {
+		jitter = 0
+	}
+
+	pacingDelay = delay
+	pacingJitter = jitter
+	nextRequest = time.Time{}
+}
+
+// Waits for the turn of the request and returns false if the context was done first.
+func waitRequestDelay(ctx context.Context) bool {
+	pacingLock.Lock()
+	if pacingDelay == 0 && pacingJitter == 0 {
+		pacingLock.Unlock()
+		return true
+	}
+
+	now := time.Now()
+	turn := nextRequest
+	if turn.Before(now) {
+		turn = now
+	}
+
+	interval := pacingDelay
+	if pacingJitter > 0 {
+		interval += time.Duration(rand.Int63n(int64(pacingJitter)))
+	}
+	nextRequest = turn.Add(interval)
+	pacingLock.Unlock()
+
+	if wait := time.Until(turn); wait > 0 {
+		return sleepContext(ctx, wait)
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRequestDelay(t *testing.T) {
+	SetRequestDelay(50*time.Millisecond, 20*time.Millisecond)
+	defer SetRequestDelay(0, 0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if !waitRequestDelay(context.Background()) {
+			t.Fatalf("The wait for request %d was interrupted", i+1)
+		}
+	}
+	// The first request is sent immediately, and each following request waits for the delay
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("Three requests took %v with a delay of 50ms and 20ms of jitter", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	SetRequestDelay(time.Second, 0)
+	waitRequestDelay(ctx)
+	if waitRequestDelay(ctx) {
+		t.Errorf("The wait did not return false after the context was cancelled")
+	}
+}
@@ -38,6 +38,8 @@ type ContextKey int
 const (
 	ContextConfig ContextKey = iota
 	ContextEventBus
+	ContextNameCounts
+	ContextNameSources
 )
 
 // Request Pub/Sub topics used across Amass.
@@ -68,16 +70,25 @@ type DNSRequest struct {
 	Records []DNSAnswer
 	Tag     string
 	Source  string
+	// The period the data source observed the name, which is zero when it's not provided
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Set when the addresses within the records are the current resolution of the name, such as those
+	// of a live scan, instead of the history of the name. Only current addresses skip the resolution
+	CurrentAddrs bool
 }
 
 // Clone implements pipeline Data.
 func (d *DNSRequest) Clone() pipeline.Data {
 	return &DNSRequest{
-		Name:    d.Name,
-		Domain:  d.Domain,
-		Records: append([]DNSAnswer(nil), d.Records...),
-		Tag:     d.Tag,
-		Source:  d.Source,
+		Name:         d.Name,
+		Domain:       d.Domain,
+		Records:      append([]DNSAnswer(nil), d.Records...),
+		Tag:          d.Tag,
+		Source:       d.Source,
+		FirstSeen:    d.FirstSeen,
+		LastSeen:     d.LastSeen,
+		CurrentAddrs: d.CurrentAddrs,
 	}
 }
 
@@ -98,6 +109,25 @@ func (d *DNSRequest) Valid() bool {
 	return true
 }
 
+// BulkDNSRequest provides several root domain names to data sources that can look them up at once.
+type BulkDNSRequest struct {
+	Domains []string
+	Tag     string
+	Source  string
+}
+
+// Clone implements pipeline Data.
+func (b *BulkDNSRequest) Clone() pipeline.Data {
+	return &BulkDNSRequest{
+		Domains: append([]string(nil), b.Domains...),
+		Tag:     b.Tag,
+		Source:  b.Source,
+	}
+}
+
+// MarkAsProcessed implements pipeline Data.
+func (b *BulkDNSRequest) MarkAsProcessed() {}
+
 // ResolvedRequest allows services to identify DNS names that have been resolved.
 
 type ResolvedRequest struct {
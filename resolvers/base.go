@@ -27,6 +27,8 @@ type baseResolver struct {
 	xchgs            *xchgManager
 	readMsgs         queue.Queue
 	wildcardChannels *wildcardChans
+	wildcardTests    int32
+	wildcardBehavior int32
 	address          string
 	log              *log.Logger
 	perSec           int
@@ -72,10 +74,12 @@ func NewBaseResolver(addr string, perSec int, logger *log.Logger) Resolver {
 			IPsAcrossLevels: make(chan *ipsAcrossLevels, 10),
 			TestResult:      make(chan *testResult, 10),
 		},
-		address: addr,
-		log:     logger,
-		perSec:  perSec,
-		conn:    conn,
+		wildcardTests:    defaultWildcardTests,
+		wildcardBehavior: WildcardSuppress,
+		address:          addr,
+		log:              logger,
+		perSec:           perSec,
+		conn:             conn,
 	}
 
 	go r.manageWildcards(r.wildcardChannels)
@@ -33,4 +33,8 @@ type Resolver interface {
 
 	// WildcardType returns the DNS wildcard type for the FQDN in the provided message.
 	WildcardType(ctx context.Context, msg *dns.Msg, domain string) int
+
+	// SetWildcardDetection assigns the number of unlikely names queried to detect each DNS wildcard,
+	// where zero selects the default of three, and the behavior for the names that match a DNS wildcard.
+	SetWildcardDetection(tests, behavior int)
 }
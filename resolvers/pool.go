@@ -210,3 +210,14 @@ func (rp *resolverPool) WildcardType(ctx context.Context, msg *dns.Msg, domain s
 	}
 	return rp.resolvers[0].WildcardType(ctx, msg, domain)
 }
+
+// SetWildcardDetection implements the Resolver interface.
+func (rp *resolverPool) SetWildcardDetection(tests, behavior int) {
+	for _, r := range rp.resolvers {
+		r.SetWildcardDetection(tests, behavior)
+	}
+
+	if rp.baseline != nil {
+		rp.baseline.SetWildcardDetection(tests, behavior)
+	}
+}
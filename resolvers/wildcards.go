@@ -7,6 +7,7 @@ import (
 	"context"
 	"math/rand"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/caffix/queue"
@@ -23,7 +24,28 @@ const (
 	LDHChars       = "abcdefghijklmnopqrstuvwxyz0123456789-"
 )
 
-const numOfWildcardTests = 3
+// The number of unlikely names queried to detect a DNS wildcard, unless another number is assigned.
+const defaultWildcardTests = 3
+
+// The behaviors available for the names that match a DNS wildcard.
+const (
+	// WildcardSuppress causes the names matching a DNS wildcard to be reported with the wildcard type
+	WildcardSuppress = iota
+	// WildcardReport causes the names matching a DNS wildcard to be logged, but reported with WildcardTypeNone
+	WildcardReport
+	// WildcardDisabled causes no DNS wildcard tests to be performed
+	WildcardDisabled
+)
+
+// SetWildcardDetection implements the Resolver interface.
+func (r *baseResolver) SetWildcardDetection(tests, behavior int) {
+	if tests <= 0 {
+		tests = defaultWildcardTests
+	}
+
+	atomic.StoreInt32(&r.wildcardTests, int32(tests))
+	atomic.StoreInt32(&r.wildcardBehavior, int32(behavior))
+}
 
 // Names for the different types of wildcards that can be detected.
 const (
@@ -40,8 +62,12 @@ var wildcardQueryTypes = []uint16{
 
 type wildcard struct {
 	WildcardType int
-	Answers      []*ExtractedAnswer
-	beingTested  bool
+	// The records common across all the unlikely name queries
+	Answers []*ExtractedAnswer
+	// Every record returned by the unlikely name queries, since a wildcard
+	// can rotate through more addresses than a single answer contains
+	Observed    []*ExtractedAnswer
+	beingTested bool
 }
 
 type wildcardChans struct {
@@ -71,7 +97,17 @@ type testResult struct {
 
 // WildcardType returns the DNS wildcard type for the provided subdomain name.
 func (r *baseResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
-	return r.wildcard(ctx, msg, domain)
+	behavior := atomic.LoadInt32(&r.wildcardBehavior)
+	if behavior == WildcardDisabled {
+		return WildcardTypeNone
+	}
+
+	wtype := r.wildcard(ctx, msg, domain)
+	if wtype != WildcardTypeNone && behavior == WildcardReport {
+		r.log.Printf("DNS wildcard match: %s: type: %d", RemoveLastDot(msg.Question[0].Name), wtype)
+		return WildcardTypeNone
+	}
+	return wtype
 }
 
 func (r *baseResolver) wildcard(ctx context.Context, msg *dns.Msg, domain string) int {
@@ -90,17 +126,8 @@ func (r *baseResolver) wildcard(ctx context.Context, msg *dns.Msg, domain string
 
 		if w.WildcardType == WildcardTypeDynamic {
 			return WildcardTypeDynamic
-		} else if w.WildcardType == WildcardTypeStatic {
-			if len(msg.Answer) == 0 {
-				return w.WildcardType
-			}
-
-			set := stringset.New()
-			insertRecordData(set, ExtractAnswers(msg))
-			intersectRecordData(set, w.Answers)
-			if set.Len() > 0 {
-				return w.WildcardType
-			}
+		} else if w.WildcardType == WildcardTypeStatic && matchesStaticWildcard(msg, w) {
+			return w.WildcardType
 		}
 	}
 
@@ -111,6 +138,24 @@ func (r *baseResolver) wildcard(ctx context.Context, msg *dns.Msg, domain string
 	})
 }
 
+// Returns true when the name in the message resolves to nothing but the records of the static DNS wildcard.
+// The addresses behind an alias can rotate, so only the alias targets are compared when the answers have them.
+func matchesStaticWildcard(msg *dns.Msg, w *wildcard) bool {
+	if len(msg.Answer) == 0 {
+		return true
+	}
+
+	ans := ExtractAnswers(msg)
+	if cnames := AnswersByType(ans, dns.TypeCNAME); len(cnames) > 0 {
+		ans = cnames
+	}
+
+	set := stringset.New()
+	insertRecordData(set, ans)
+	subtractRecordData(set, w.Observed)
+	return set.Len() == 0
+}
+
 func (r *baseResolver) fetchWildcardType(ctx context.Context, sub string) *wildcard {
 	ch := make(chan *wildcard, 2)
 
@@ -230,7 +275,8 @@ func (r *baseResolver) wildcardTest(ctx context.Context, sub string) {
 	var answers []*ExtractedAnswer
 
 	// Query multiple times with unlikely names against this subdomain
-	for i := 0; i < numOfWildcardTests; i++ {
+	tests := int(atomic.LoadInt32(&r.wildcardTests))
+	for i := 0; i < tests; i++ {
 		var name string
 
 		// Generate the unlikely label / name
@@ -260,7 +306,8 @@ func (r *baseResolver) wildcardTest(ctx context.Context, sub string) {
 	}
 
 	already := stringset.New()
-	var final []*ExtractedAnswer
+	observed := stringset.New()
+	var final, all []*ExtractedAnswer
 	// Create the slice of answers common across all the unlikely name queries
 	for _, a := range answers {
 		a.Data = strings.Trim(a.Data, ".")
@@ -269,6 +316,10 @@ func (r *baseResolver) wildcardTest(ctx context.Context, sub string) {
 			final = append(final, a)
 			already.Insert(a.Data)
 		}
+		if !observed.Has(a.Data) {
+			all = append(all, a)
+			observed.Insert(a.Data)
+		}
 	}
 
 	// Determine whether the subdomain has a DNS wildcard, and if so, which type is it?
@@ -288,6 +339,7 @@ func (r *baseResolver) wildcardTest(ctx context.Context, sub string) {
 		Result: &wildcard{
 			WildcardType: wildcardType,
 			Answers:      final,
+			Observed:     all,
 			beingTested:  false,
 		},
 	}
@@ -335,6 +387,16 @@ func intersectRecordData(set stringset.Set, ans []*ExtractedAnswer) {
 	set.Intersect(records)
 }
 
+func subtractRecordData(set stringset.Set, ans []*ExtractedAnswer) {
+	records := stringset.New()
+
+	for _, a := range ans {
+		records.Insert(strings.Trim(a.Data, "."))
+	}
+
+	set.Subtract(records)
+}
+
 func insertRecordData(set stringset.Set, ans []*ExtractedAnswer) {
 	records := stringset.New()
 
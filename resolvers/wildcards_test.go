@@ -0,0 +1,92 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestMatchesStaticWildcard(t *testing.T) {
+	// The unlikely name queries sampled two of the addresses the wildcard rotates through
+	w := &wildcard{
+		WildcardType: WildcardTypeStatic,
+		Answers: []*ExtractedAnswer{
+			{Type: dns.TypeA, Data: "192.0.2.1"},
+		},
+		Observed: []*ExtractedAnswer{
+			{Type: dns.TypeA, Data: "192.0.2.1"},
+			{Type: dns.TypeA, Data: "192.0.2.2"},
+			{Type: dns.TypeCNAME, Data: "wild.example.com"},
+		},
+	}
+
+	tests := []struct {
+		label    string
+		records  []string
+		expected bool
+	}{
+		{"No answers", nil, true},
+		{"A records of the wildcard", []string{
+			"www.example.com. 300 IN A 192.0.2.1",
+		}, true},
+		{"A records sampled by a single query", []string{
+			"www.example.com. 300 IN A 192.0.2.2",
+		}, true},
+		{"A records beyond the wildcard", []string{
+			"www.example.com. 300 IN A 192.0.2.1",
+			"www.example.com. 300 IN A 198.51.100.7",
+		}, false},
+		{"A records not seen by the queries", []string{
+			"www.example.com. 300 IN A 198.51.100.7",
+		}, false},
+		{"CNAME chain to the wildcard target", []string{
+			"www.example.com. 300 IN CNAME wild.example.com.",
+			"wild.example.com. 300 IN A 203.0.113.9",
+		}, true},
+		{"CNAME chain to another target", []string{
+			"www.example.com. 300 IN CNAME www.cdn.example.net.",
+			"www.cdn.example.net. 300 IN A 192.0.2.1",
+		}, false},
+	}
+
+	for _, test := range tests {
+		msg := QueryMsg("www.example.com", dns.TypeA)
+		for _, record := range test.records {
+			rr, err := dns.NewRR(record)
+			if err != nil {
+				t.Fatalf("%s: failed to parse the record %s: %v", test.label, record, err)
+			}
+			msg.Answer = append(msg.Answer, rr)
+		}
+
+		if got := matchesStaticWildcard(msg, w); got != test.expected {
+			t.Errorf("%s: matchesStaticWildcard returned %t instead of %t", test.label, got, test.expected)
+		}
+	}
+}
+
+func TestSetWildcardDetection(t *testing.T) {
+	first := &baseResolver{wildcardTests: defaultWildcardTests, wildcardBehavior: WildcardSuppress}
+	second := &baseResolver{wildcardTests: defaultWildcardTests, wildcardBehavior: WildcardSuppress}
+	pool := &resolverPool{resolvers: []Resolver{first}}
+
+	pool.SetWildcardDetection(5, WildcardDisabled)
+	if first.wildcardTests != 5 || first.wildcardBehavior != WildcardDisabled {
+		t.Errorf("The pool did not assign the wildcard detection to its resolvers")
+	}
+	if second.wildcardTests != defaultWildcardTests || second.wildcardBehavior != WildcardSuppress {
+		t.Errorf("The wildcard detection of one resolver changed another")
+	}
+	if wtype := first.WildcardType(context.Background(), QueryMsg("www.example.com", dns.TypeA), "example.com"); wtype != WildcardTypeNone {
+		t.Errorf("The resolver with the wildcard detection disabled returned the type %d", wtype)
+	}
+
+	first.SetWildcardDetection(0, WildcardReport)
+	if first.wildcardTests != defaultWildcardTests {
+		t.Errorf("Zero wildcard tests did not select the default of %d", defaultWildcardTests)
+	}
+}
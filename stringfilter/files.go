@@ -0,0 +1,65 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package stringfilter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/caffix/stringset"
+)
+
+// SaveSetToFile writes the members of the Set to the file at path, one sorted member per line.
+// The file is created or truncated.
+func SaveSetToFile(path string, set stringset.Set) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to open the set file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	members := set.Slice()
+	sort.Strings(members)
+
+	w := bufio.NewWriter(f)
+	for _, member := range members {
+		if _, err := w.WriteString(member + "\n"); err != nil {
+			return fmt.Errorf("Failed to write the set file %s: %v", path, err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("Failed to write the set file %s: %v", path, err)
+	}
+	return f.Close()
+}
+
+// LoadSetFromFile inserts each line of the file at path into the provided Set, creating the Set
+// when it is nil, and returns it. Blank lines are skipped and surrounding whitespace is removed.
+func LoadSetFromFile(path string, set stringset.Set) (stringset.Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return set, fmt.Errorf("Failed to open the set file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	if set == nil {
+		set = stringset.New()
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if member := strings.TrimSpace(scanner.Text()); member != "" {
+			set.Insert(member)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return set, fmt.Errorf("Failed to read the set file %s: %v", path, err)
+	}
+	return set, nil
+}
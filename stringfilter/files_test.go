@@ -0,0 +1,60 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package stringfilter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/caffix/stringset"
+)
+
+func TestSaveLoadSetFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stringfilter")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "names.txt")
+	set := stringset.New("www.owasp.org", "dev.api.owasp.org", "owasp.org")
+	if err := SaveSetToFile(path, set); err != nil {
+		t.Fatalf("SaveSetToFile returned an error: %v", err)
+	}
+
+	loaded, err := LoadSetFromFile(path, nil)
+	if err != nil {
+		t.Fatalf("LoadSetFromFile returned an error: %v", err)
+	}
+	if !sameMembers(loaded, set.Slice()) {
+		t.Errorf("LoadSetFromFile returned %v, expected %v", loaded.Slice(), set.Slice())
+	}
+}
+
+func TestLoadSetFromFileWhitespace(t *testing.T) {
+	f, err := ioutil.TempFile("", "stringfilter")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString("www.owasp.org  \n\n\t\n  owasp.org\r\nwww.owasp.org\n"); err != nil {
+		t.Fatalf("Failed to write the temporary file: %v", err)
+	}
+	f.Close()
+
+	set, err := LoadSetFromFile(f.Name(), stringset.New("example.com"))
+	if err != nil {
+		t.Fatalf("LoadSetFromFile returned an error: %v", err)
+	}
+	if want := []string{"www.owasp.org", "owasp.org", "example.com"}; !sameMembers(set, want) {
+		t.Errorf("LoadSetFromFile returned %v, expected %v", set.Slice(), want)
+	}
+
+	if _, err := LoadSetFromFile(filepath.Join(os.TempDir(), "stringfilter-missing.txt"), nil); err == nil {
+		t.Errorf("LoadSetFromFile did not return an error for a missing file")
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package stringfilter
+
+import (
+	"regexp"
+
+	"github.com/caffix/stringset"
+)
+
+// FilterMatching returns a new Set containing the members of the provided Set that match the regular expression.
+// The members of a Set are lowercase, so the expression should not expect uppercase characters.
+func FilterMatching(set stringset.Set, re *regexp.Regexp) stringset.Set {
+	return filterSet(set, re, true)
+}
+
+// FilterNotMatching returns a new Set containing the members of the provided Set that do not match the regular expression.
+func FilterNotMatching(set stringset.Set, re *regexp.Regexp) stringset.Set {
+	return filterSet(set, re, false)
+}
+
+func filterSet(set stringset.Set, re *regexp.Regexp, match bool) stringset.Set {
+	results := stringset.New()
+
+	for member := range set {
+		if re.MatchString(member) == match {
+			results.Insert(member)
+		}
+	}
+	return results
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package stringfilter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/caffix/stringset"
+)
+
+func TestFilterMatching(t *testing.T) {
+	set := stringset.New("www.owasp.org", "dev.api.owasp.org", "owasp.org.example.com", "example.com")
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`^.+\.owasp\.org$`, []string{"www.owasp.org", "dev.api.owasp.org"}},
+		{`api\.owasp`, []string{"dev.api.owasp.org"}},
+		{`owasp\.org`, []string{"www.owasp.org", "dev.api.owasp.org", "owasp.org.example.com"}},
+		{`^owasp\.org$`, nil},
+	}
+
+	for _, test := range tests {
+		re := regexp.MustCompile(test.pattern)
+
+		got := FilterMatching(set, re)
+		if want := stringset.New(test.want...); got.Len() != want.Len() || !hasAll(got, want) {
+			t.Errorf("FilterMatching with %s returned %v, expected %v", test.pattern, got.Slice(), test.want)
+		}
+
+		rest := FilterNotMatching(set, re)
+		if rest.Len() != set.Len()-got.Len() {
+			t.Errorf("FilterNotMatching with %s returned %v", test.pattern, rest.Slice())
+		}
+		for member := range rest {
+			if got.Has(member) {
+				t.Errorf("FilterNotMatching with %s returned the matching member %s", test.pattern, member)
+			}
+		}
+	}
+
+	if set.Len() != 4 {
+		t.Errorf("The filters modified the provided set")
+	}
+}
+
+func hasAll(set, other stringset.Set) bool {
+	for member := range other {
+		if !set.Has(member) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package stringfilter
+
+import "github.com/caffix/stringset"
+
+// Union returns a new Set containing the members of both provided Sets.
+func Union(set, other stringset.Set) stringset.Set {
+	results := stringset.New(set.Slice()...)
+
+	results.Union(other)
+	return results
+}
+
+// Intersect returns a new Set containing the members found in both provided Sets.
+func Intersect(set, other stringset.Set) stringset.Set {
+	results := stringset.New()
+
+	for member := range set {
+		if other.Has(member) {
+			results.Insert(member)
+		}
+	}
+	return results
+}
+
+// Difference returns a new Set containing the members of set that are not found in other,
+// such as the names discovered since a previous enumeration.
+func Difference(set, other stringset.Set) stringset.Set {
+	results := stringset.New()
+
+	for member := range set {
+		if !other.Has(member) {
+			results.Insert(member)
+		}
+	}
+	return results
+}
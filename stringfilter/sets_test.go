@@ -0,0 +1,72 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package stringfilter
+
+import (
+	"testing"
+
+	"github.com/caffix/stringset"
+)
+
+func TestSetOperations(t *testing.T) {
+	tests := []struct {
+		name      string
+		set       []string
+		other     []string
+		union     []string
+		intersect []string
+		diff      []string
+	}{
+		{"empty sets", nil, nil, nil, nil, nil},
+		{"empty other", []string{"a.owasp.org"}, nil, []string{"a.owasp.org"}, nil, []string{"a.owasp.org"}},
+		{"empty set", nil, []string{"a.owasp.org"}, []string{"a.owasp.org"}, nil, nil},
+		{
+			"disjoint sets",
+			[]string{"a.owasp.org", "b.owasp.org"},
+			[]string{"c.owasp.org"},
+			[]string{"a.owasp.org", "b.owasp.org", "c.owasp.org"},
+			nil,
+			[]string{"a.owasp.org", "b.owasp.org"},
+		},
+		{
+			"overlapping sets",
+			[]string{"a.owasp.org", "b.owasp.org"},
+			[]string{"b.owasp.org", "c.owasp.org"},
+			[]string{"a.owasp.org", "b.owasp.org", "c.owasp.org"},
+			[]string{"b.owasp.org"},
+			[]string{"a.owasp.org"},
+		},
+	}
+
+	for _, test := range tests {
+		set := stringset.New(test.set...)
+		other := stringset.New(test.other...)
+
+		if got := Union(set, other); !sameMembers(got, test.union) {
+			t.Errorf("%s: Union returned %v, expected %v", test.name, got.Slice(), test.union)
+		}
+		if got := Intersect(set, other); !sameMembers(got, test.intersect) {
+			t.Errorf("%s: Intersect returned %v, expected %v", test.name, got.Slice(), test.intersect)
+		}
+		if got := Difference(set, other); !sameMembers(got, test.diff) {
+			t.Errorf("%s: Difference returned %v, expected %v", test.name, got.Slice(), test.diff)
+		}
+		if set.Len() != len(test.set) || other.Len() != len(test.other) {
+			t.Errorf("%s: The operations modified the provided sets", test.name)
+		}
+	}
+}
+
+func sameMembers(set stringset.Set, want []string) bool {
+	if set.Len() != len(want) {
+		return false
+	}
+
+	for _, member := range want {
+		if !set.Has(member) {
+			return false
+		}
+	}
+	return true
+}
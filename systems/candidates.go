@@ -0,0 +1,60 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"sync"
+
+	"github.com/caffix/stringset"
+)
+
+// Candidates tracks the names provided by the data sources and which of them were resolved.
+type Candidates struct {
+	emitted *NameSources
+
+	sync.Mutex
+	resolved stringset.Set
+}
+
+// NewCandidates returns an initialized Candidates object.
+func NewCandidates() *Candidates {
+	return &Candidates{
+		emitted:  NewNameSources(),
+		resolved: stringset.New(),
+	}
+}
+
+// Emitted records that the named data source provided the DNS name.
+func (c *Candidates) Emitted(name, source string) {
+	c.emitted.Add(name, source)
+}
+
+// Sources returns the sorted names of the data sources that provided the DNS name.
+func (c *Candidates) Sources(name string) []string {
+	return c.emitted.Sources(name)
+}
+
+// Resolved records that the DNS name was successfully resolved.
+func (c *Candidates) Resolved(name string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.resolved.Insert(name)
+}
+
+// Unresolved returns the names provided by the data sources that were never resolved,
+// along with the sorted names of the data sources that provided each of them.
+func (c *Candidates) Unresolved() map[string][]string {
+	results := c.emitted.All()
+
+	c.Lock()
+	defer c.Unlock()
+
+	for name := range results {
+		if c.resolved.Has(name) {
+			delete(results, name)
+		}
+	}
+	return results
+}
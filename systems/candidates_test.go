@@ -0,0 +1,31 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCandidatesSources(t *testing.T) {
+	c := NewCandidates()
+
+	c.Emitted("www.example.com", "Wayback")
+	c.Emitted("www.example.com", "Crtsh")
+	c.Emitted("www.example.com", "Wayback")
+	c.Emitted("mail.example.com", "DNSDB")
+	c.Resolved("mail.example.com")
+
+	if srcs := c.Sources("www.example.com"); !reflect.DeepEqual(srcs, []string{"Crtsh", "Wayback"}) {
+		t.Errorf("The data sources of the name were not returned sorted and once each: %v", srcs)
+	}
+	if srcs := c.Sources("ftp.example.com"); len(srcs) != 0 {
+		t.Errorf("Data sources were returned for a name that was never provided: %v", srcs)
+	}
+
+	unresolved := c.Unresolved()
+	if _, found := unresolved["mail.example.com"]; found || len(unresolved) != 1 {
+		t.Errorf("The resolved name was returned with the unresolved names: %v", unresolved)
+	}
+}
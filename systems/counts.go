@@ -0,0 +1,31 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import "sync"
+
+// NameCounts tracks the number of names provided by each data source during an enumeration,
+// so the maximum number of names permitted for a data source can be enforced.
+type NameCounts struct {
+	sync.Mutex
+	counts map[string]int
+}
+
+// NewNameCounts returns an initialized NameCounts object.
+func NewNameCounts() *NameCounts {
+	return &NameCounts{counts: make(map[string]int)}
+}
+
+// Accept counts the name provided by the named data source and reports whether it is within the
+// maximum number of names. The count returned is the number of names provided before this one.
+func (n *NameCounts) Accept(source string, max int) (int, bool) {
+	n.Lock()
+	defer n.Unlock()
+
+	count := n.counts[source]
+	if count <= max {
+		n.counts[source] = count + 1
+	}
+	return count, count < max
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import "testing"
+
+func TestNameCountsAccept(t *testing.T) {
+	n := NewNameCounts()
+
+	for i := 0; i < 2; i++ {
+		if count, ok := n.Accept("Crtsh", 2); !ok || count != i {
+			t.Errorf("Name %d was not accepted within the maximum: count %d", i+1, count)
+		}
+	}
+	if count, ok := n.Accept("Crtsh", 2); ok || count != 2 {
+		t.Errorf("The name beyond the maximum was accepted: count %d", count)
+	}
+	// The count stops once past the maximum, so the cap being reached is only reported once
+	if count, ok := n.Accept("Crtsh", 2); ok || count != 3 {
+		t.Errorf("The names beyond the maximum were not dropped: count %d", count)
+	}
+	if count, ok := n.Accept("Crtsh", 2); ok || count != 3 {
+		t.Errorf("The count continued past the maximum: count %d", count)
+	}
+
+	if _, ok := n.Accept("Wayback", 2); !ok {
+		t.Error("The maximum of one data source was applied to another")
+	}
+	if _, ok := NewNameCounts().Accept("Crtsh", 2); !ok {
+		t.Error("The names counted by another enumeration were applied")
+	}
+}
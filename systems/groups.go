@@ -0,0 +1,51 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/stringset"
+)
+
+// SourceGroups tracks the names discovered by the enumeration under each data source that found them.
+type SourceGroups struct {
+	sync.Mutex
+	groups map[string]stringset.Set
+}
+
+// NewSourceGroups returns an initialized SourceGroups object.
+func NewSourceGroups() *SourceGroups {
+	return &SourceGroups{groups: make(map[string]stringset.Set)}
+}
+
+// Add records the name of the output under each of the data sources attributed to it.
+func (g *SourceGroups) Add(out *requests.Output) {
+	g.Lock()
+	defer g.Unlock()
+
+	for _, src := range out.Sources {
+		if _, found := g.groups[src]; !found {
+			g.groups[src] = stringset.New()
+		}
+		g.groups[src].Insert(out.Name)
+	}
+}
+
+// Groups returns the sorted names recorded for each data source. A name found
+// by several data sources appears within the group of each of them.
+func (g *SourceGroups) Groups() map[string][]string {
+	g.Lock()
+	defer g.Unlock()
+
+	results := make(map[string][]string, len(g.groups))
+	for src, names := range g.groups {
+		list := names.Slice()
+		sort.Strings(list)
+		results[src] = list
+	}
+	return results
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import "context"
+
+// DefaultMaxCrawls is the number of concurrent crawls allowed when the configuration has no limit.
+const DefaultMaxCrawls = 50
+
+// Limiter limits the number of activities, such as web crawls, performed concurrently across the System.
+type Limiter struct {
+	tokens chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing max concurrent activities, or a single activity when max
+// is not a positive number.
+func NewLimiter(max int) *Limiter {
+	if max <= 0 {
+		max = 1
+	}
+
+	tokens := make(chan struct{}, max)
+	for i := 0; i < max; i++ {
+		tokens <- struct{}{}
+	}
+	return &Limiter{tokens: tokens}
+}
+
+// Acquire blocks until an activity can be started, and returns false if the context expires first.
+// Each successful call must be followed by a call to Release.
+func (l *Limiter) Acquire(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-l.tokens:
+		return true
+	}
+}
+
+// Release allows another activity to be started.
+func (l *Limiter) Release() {
+	l.tokens <- struct{}{}
+}
+
+// Max returns the number of activities that can be performed concurrently.
+func (l *Limiter) Max() int {
+	return cap(l.tokens)
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/OWASP/Amass/v3/graph"
 	"github.com/OWASP/Amass/v3/limits"
 	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/caffix/service"
@@ -29,17 +31,53 @@ type LocalSystem struct {
 	pool              resolvers.Resolver
 	graphs            []*graph.Graph
 	cache             *amassnet.ASNCache
+	metrics           *Metrics
+	candidates        *Candidates
+	pivots            *Pivots
+	groups            *SourceGroups
+	stream            *NameStream
+	doh               *amassdns.DoHClient
+	crawls            *Limiter
+	resolutions       *Limiter
 	done              chan struct{}
 	doneAlreadyClosed bool
 	addSource         chan service.Service
 	allSources        chan chan []service.Service
 }
 
-// NewLocalSystem returns an initialized LocalSystem object.
+// NewLocalSystem returns an initialized LocalSystem object. The web request settings of the
+// configuration are applied to the process-wide state of the net/http package, so they replace
+// the settings of any System created previously. The transport of the configuration is only
+// used for the requests of this System.
 func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 	if err := c.CheckSettings(); err != nil {
 		return nil, err
 	}
+	http.SetConnectionPool(c.MaxIdleConns, c.MaxIdleConnsPerHost, time.Duration(c.IdleConnTimeout)*time.Second)
+	if c.Proxy != "" {
+		if err := http.SetProxy(c.Proxy); err != nil {
+			return nil, err
+		}
+	} else {
+		http.ResetProxy()
+	}
+	http.SetRetryPolicy(c.RequestRetries, c.RequestRetryDelay)
+	http.SetMaxRetryAfter(c.MaxRetryAfter)
+	http.SetRequestDelay(c.RequestDelay, c.RequestJitter)
+	http.SetCircuitBreaker(c.CircuitBreakerThreshold, c.CircuitBreakerCooldown, c.Log)
+	http.SetMaxResponseSize(c.MaxResponseSize)
+	if err := http.SetResponseArchive(c.ResponseArchive, c.Log); err != nil {
+		return nil, err
+	}
+	http.SetUserAgents(c.UserAgents)
+	http.SetStrictNameMatching(c.StrictNameMatching)
+	http.SetAllowSingleLabelNames(c.AllowSingleLabelNames)
+	// Logging each web request is only enabled for verbose output, since it can be very noisy
+	if c.Verbose {
+		http.SetRequestLogger(c.Log)
+	} else {
+		http.SetRequestLogger(nil)
+	}
 
 	max := int(float64(limits.GetFileLimit()) * 0.7)
 
@@ -53,10 +91,21 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 		return nil, errors.New("The system was unable to build the pool of resolvers")
 	}
 
+	switch c.WildcardMode {
+	case config.WildcardModeReport:
+		pool.SetWildcardDetection(c.WildcardTests, resolvers.WildcardReport)
+	case config.WildcardModeOff:
+		pool.SetWildcardDetection(c.WildcardTests, resolvers.WildcardDisabled)
+	default:
+		pool.SetWildcardDetection(c.WildcardTests, resolvers.WildcardSuppress)
+	}
+
 	sys := &LocalSystem{
 		cfg:        c,
 		pool:       pool,
 		cache:      amassnet.NewASNCache(),
+		metrics:    NewMetrics(),
+		crawls:     newCrawlLimiter(c.MaxConcurrentCrawls),
 		done:       make(chan struct{}, 2),
 		addSource:  make(chan service.Service),
 		allSources: make(chan chan []service.Service, 10),
@@ -78,6 +127,26 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 		return nil, err
 	}
 
+	if c.MaxConcurrentResolutions > 0 {
+		sys.resolutions = NewLimiter(c.MaxConcurrentResolutions)
+	}
+	// Keeping every candidate name requires memory in proportion to the names provided
+	if c.CollectUnresolved {
+		sys.candidates = NewCandidates()
+	}
+	if c.GroupBySource {
+		sys.groups = NewSourceGroups()
+	}
+	if c.CollectPivots {
+		sys.pivots = NewPivots(c.PivotAllNames)
+	}
+	if c.NameStream != nil {
+		sys.stream = NewNameStream(c.NameStream)
+	}
+	if c.DoHServer != "" {
+		sys.doh = sys.newDoHClient()
+	}
+
 	go sys.manageDataSources()
 	return sys, nil
 }
@@ -97,6 +166,64 @@ func (l *LocalSystem) Cache() *amassnet.ASNCache {
 	return l.cache
 }
 
+// Candidates implements the System interface.
+func (l *LocalSystem) Candidates() *Candidates {
+	return l.candidates
+}
+
+// SourceGroups implements the System interface.
+func (l *LocalSystem) SourceGroups() *SourceGroups {
+	return l.groups
+}
+
+// Pivots implements the System interface.
+func (l *LocalSystem) Pivots() *Pivots {
+	return l.pivots
+}
+
+// NameStream implements the System interface.
+func (l *LocalSystem) NameStream() *NameStream {
+	return l.stream
+}
+
+// DoH implements the System interface.
+func (l *LocalSystem) DoH() *amassdns.DoHClient {
+	return l.doh
+}
+
+func (l *LocalSystem) newDoHClient() *amassdns.DoHClient {
+	c := amassdns.NewDoHClient(l.cfg.DoHServer)
+
+	if l.cfg.DoHFallback {
+		c.SetFallback(func(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+			return l.pool.Query(ctx, msg, resolvers.PriorityHigh, resolvers.PoolRetryPolicy)
+		})
+	}
+	return c
+}
+
+// CrawlLimiter implements the System interface.
+func (l *LocalSystem) CrawlLimiter() *Limiter {
+	return l.crawls
+}
+
+// ResolutionLimiter implements the System interface.
+func (l *LocalSystem) ResolutionLimiter() *Limiter {
+	return l.resolutions
+}
+
+func newCrawlLimiter(max int) *Limiter {
+	if max <= 0 {
+		max = DefaultMaxCrawls
+	}
+	return NewLimiter(max)
+}
+
+// Metrics implements the System interface.
+func (l *LocalSystem) Metrics() *Metrics {
+	return l.metrics
+}
+
 // AddSource implements the System interface.
 func (l *LocalSystem) AddSource(src service.Service) error {
 	l.addSource <- src
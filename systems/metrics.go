@@ -0,0 +1,138 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SourceMetrics contains the measurements recorded for a data source.
+type SourceMetrics struct {
+	Requests int
+	Names    int
+	Errors   int
+	Duration time.Duration
+}
+
+// Metrics collects the SourceMetrics for each data source used by the System.
+type Metrics struct {
+	sync.Mutex
+	sources map[string]*SourceMetrics
+	// The number of requests each data source is handling at the moment
+	inProgress map[string]int
+}
+
+// NewMetrics returns an initialized Metrics object.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		sources:    make(map[string]*SourceMetrics),
+		inProgress: make(map[string]int),
+	}
+}
+
+// Started records that the named data source began handling a request.
+func (m *Metrics) Started(source string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.inProgress[source]++
+}
+
+// Finished records that the named data source completed a request previously started.
+func (m *Metrics) Finished(source string) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.inProgress[source] <= 1 {
+		delete(m.inProgress, source)
+		return
+	}
+	m.inProgress[source]--
+}
+
+// InProgress returns the number of requests the named data source is handling.
+func (m *Metrics) InProgress(source string) int {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.inProgress[source]
+}
+
+// Record adds the measurements provided to the metrics of the named data source.
+func (m *Metrics) Record(source string, sm SourceMetrics) {
+	m.Lock()
+	defer m.Unlock()
+
+	cur, found := m.sources[source]
+	if !found {
+		cur = new(SourceMetrics)
+		m.sources[source] = cur
+	}
+
+	cur.Requests += sm.Requests
+	cur.Names += sm.Names
+	cur.Errors += sm.Errors
+	cur.Duration += sm.Duration
+}
+
+// Get returns the metrics recorded for the named data source.
+func (m *Metrics) Get(source string) SourceMetrics {
+	m.Lock()
+	defer m.Unlock()
+
+	if sm, found := m.sources[source]; found {
+		return *sm
+	}
+	return SourceMetrics{}
+}
+
+// The entry for each data source in the JSON report of the metrics.
+type sourceReport struct {
+	Source   string  `json:"source"`
+	Requests int     `json:"requests"`
+	Names    int     `json:"names"`
+	Errors   int     `json:"errors"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// JSON returns a report of the metrics recorded for each data source, sorted by name.
+func (m *Metrics) JSON() ([]byte, error) {
+	m.Lock()
+	report := make([]sourceReport, 0, len(m.sources))
+	for name, sm := range m.sources {
+		report = append(report, sourceReport{
+			Source:   name,
+			Requests: sm.Requests,
+			Names:    sm.Names,
+			Errors:   sm.Errors,
+			Seconds:  sm.Duration.Seconds(),
+		})
+	}
+	m.Unlock()
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Source < report[j].Source
+	})
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// ReportYield returns the number of names provided by each data source according to a report
+// previously returned by JSON. The yield is kept apart from the recorded metrics, so the
+// report of the current enumeration does not include the measurements of earlier ones.
+func ReportYield(data []byte) (map[string]int, error) {
+	var report []sourceReport
+
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	yield := make(map[string]int, len(report))
+	for _, sr := range report {
+		yield[sr.Source] += sr.Names
+	}
+	return yield, nil
+}
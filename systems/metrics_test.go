@@ -0,0 +1,68 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetricsRecord(t *testing.T) {
+	m := NewMetrics()
+
+	m.Record("AlienVault", SourceMetrics{Requests: 1})
+	m.Record("AlienVault", SourceMetrics{Requests: 1, Errors: 1})
+	m.Record("AlienVault", SourceMetrics{Names: 5, Duration: time.Second})
+
+	if sm := m.Get("AlienVault"); sm.Requests != 2 || sm.Errors != 1 || sm.Names != 5 || sm.Duration != time.Second {
+		t.Errorf("The recorded metrics were not accumulated: %+v", sm)
+	}
+	if sm := m.Get("DNSDB"); sm != (SourceMetrics{}) {
+		t.Errorf("Metrics were returned for a data source that recorded none: %+v", sm)
+	}
+}
+
+func TestMetricsInProgress(t *testing.T) {
+	m := NewMetrics()
+
+	m.Started("AlienVault")
+	m.Started("AlienVault")
+	m.Finished("AlienVault")
+	if num := m.InProgress("AlienVault"); num != 1 {
+		t.Errorf("The data source had %d requests in progress instead of 1", num)
+	}
+
+	m.Finished("AlienVault")
+	if num := m.InProgress("AlienVault"); num != 0 {
+		t.Errorf("The data source had %d requests in progress after finishing them", num)
+	}
+}
+
+func TestReportYield(t *testing.T) {
+	m := NewMetrics()
+	m.Record("AlienVault", SourceMetrics{Requests: 3, Names: 10, Errors: 1, Duration: 2 * time.Second})
+	m.Record("DNSDB", SourceMetrics{Requests: 1})
+
+	data, err := m.JSON()
+	if err != nil {
+		t.Fatalf("JSON returned an error: %v", err)
+	}
+
+	yield, err := ReportYield(data)
+	if err != nil {
+		t.Fatalf("ReportYield returned an error: %v", err)
+	}
+	if expected := map[string]int{"AlienVault": 10, "DNSDB": 0}; !reflect.DeepEqual(yield, expected) {
+		t.Errorf("ReportYield returned %v, expected %v", yield, expected)
+	}
+	// The yield of the report is not added to the recorded metrics
+	if sm := m.Get("AlienVault"); sm.Names != 10 {
+		t.Errorf("ReportYield changed the recorded metrics: %+v", sm)
+	}
+
+	if _, err := ReportYield([]byte("not json")); err == nil {
+		t.Errorf("ReportYield did not return an error for an invalid report")
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"sort"
+	"sync"
+)
+
+// NameSources tracks the names provided by the data sources during an enumeration, along with the
+// data sources that provided each of them. The names provided again can then be dropped, while
+// every data source that found a name is still attributed.
+type NameSources struct {
+	sync.Mutex
+	// The data source names are kept as provided, since the sets lowercase their members
+	sources map[string]map[string]struct{}
+}
+
+// NewNameSources returns an initialized NameSources object.
+func NewNameSources() *NameSources {
+	return &NameSources{sources: make(map[string]map[string]struct{})}
+}
+
+// Has returns true when a data source already provided the DNS name.
+func (n *NameSources) Has(name string) bool {
+	n.Lock()
+	defer n.Unlock()
+
+	_, found := n.sources[name]
+	return found
+}
+
+// Add records that the named data source provided the DNS name, and returns
+// true when no data source provided the name before.
+func (n *NameSources) Add(name, source string) bool {
+	n.Lock()
+	defer n.Unlock()
+
+	srcs, found := n.sources[name]
+	if !found {
+		srcs = make(map[string]struct{})
+		n.sources[name] = srcs
+	}
+	srcs[source] = struct{}{}
+	return !found
+}
+
+// Sources returns the sorted names of the data sources that provided the DNS name.
+func (n *NameSources) Sources(name string) []string {
+	n.Lock()
+	defer n.Unlock()
+
+	srcs, found := n.sources[name]
+	if !found {
+		return nil
+	}
+	return sortedSources(srcs)
+}
+
+// All returns every DNS name provided, along with the sorted names of the data sources that provided it.
+func (n *NameSources) All() map[string][]string {
+	n.Lock()
+	defer n.Unlock()
+
+	results := make(map[string][]string, len(n.sources))
+	for name, srcs := range n.sources {
+		results[name] = sortedSources(srcs)
+	}
+	return results
+}
+
+func sortedSources(sources map[string]struct{}) []string {
+	srcs := make([]string, 0, len(sources))
+	for src := range sources {
+		srcs = append(srcs, src)
+	}
+
+	sort.Strings(srcs)
+	return srcs
+}
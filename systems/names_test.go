@@ -0,0 +1,34 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNameSources(t *testing.T) {
+	n := NewNameSources()
+
+	if !n.Add("www.example.com", "Wayback") {
+		t.Errorf("The first data source providing the name was not reported as new")
+	}
+	if n.Add("www.example.com", "Crtsh") || n.Add("www.example.com", "Wayback") {
+		t.Errorf("The name provided again was reported as new")
+	}
+	if !n.Has("www.example.com") || n.Has("ftp.example.com") {
+		t.Errorf("Has did not report only the names provided")
+	}
+	if srcs := n.Sources("www.example.com"); !reflect.DeepEqual(srcs, []string{"Crtsh", "Wayback"}) {
+		t.Errorf("The data sources of the name were not returned sorted and once each: %v", srcs)
+	}
+	if srcs := n.Sources("ftp.example.com"); len(srcs) != 0 {
+		t.Errorf("Data sources were returned for a name that was never provided: %v", srcs)
+	}
+
+	expected := map[string][]string{"www.example.com": {"Crtsh", "Wayback"}}
+	if all := n.All(); !reflect.DeepEqual(all, expected) {
+		t.Errorf("All returned %v instead of %v", all, expected)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/caffix/stringset"
+)
+
+// The shortest label of a root domain name that relates the out of scope names containing it.
+const minPivotLabelLen = 4
+
+// Pivots tracks the names provided by the data sources that are outside the scope of the enumeration.
+// The names that appear related to the scope can be reviewed to deliberately expand it.
+type Pivots struct {
+	sync.Mutex
+	all     bool
+	dropped map[string]*droppedName
+	// The addresses provided for the names within the scope
+	addrs stringset.Set
+}
+
+type droppedName struct {
+	sources stringset.Set
+	addrs   stringset.Set
+}
+
+// NewPivots returns an initialized Pivots object. When all is true, every out of scope name
+// is a pivot candidate, instead of only the names related to the scope.
+func NewPivots(all bool) *Pivots {
+	return &Pivots{
+		all:     all,
+		dropped: make(map[string]*droppedName),
+		addrs:   stringset.New(),
+	}
+}
+
+// Dropped records that the named data source provided the out of scope DNS name, along with its addresses.
+func (p *Pivots) Dropped(name, source string, addrs []string) {
+	p.Lock()
+	defer p.Unlock()
+
+	d, found := p.dropped[name]
+	if !found {
+		d = &droppedName{
+			sources: stringset.New(),
+			addrs:   stringset.New(),
+		}
+		p.dropped[name] = d
+	}
+
+	d.sources.Insert(source)
+	d.addrs.InsertMany(addrs...)
+}
+
+// InScope records the addresses provided for a DNS name within the scope of the enumeration.
+func (p *Pivots) InScope(addrs []string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.addrs.InsertMany(addrs...)
+}
+
+// Candidates returns the out of scope names that share an address with a name in scope or contain
+// the first label of one of the root domain names, along with the sorted names of the data sources
+// that provided each of them. Every out of scope name is returned when the object was created for all.
+func (p *Pivots) Candidates(domains []string) map[string][]string {
+	p.Lock()
+	defer p.Unlock()
+
+	var labels []string
+	for _, domain := range domains {
+		if label := strings.Split(strings.ToLower(domain), ".")[0]; len(label) >= minPivotLabelLen {
+			labels = append(labels, label)
+		}
+	}
+
+	results := make(map[string][]string)
+	for name, d := range p.dropped {
+		if !p.all && !p.related(name, d, labels) {
+			continue
+		}
+
+		srcs := d.sources.Slice()
+		sort.Strings(srcs)
+		results[name] = srcs
+	}
+	return results
+}
+
+func (p *Pivots) related(name string, d *droppedName, labels []string) bool {
+	for _, label := range labels {
+		if strings.Contains(name, label) {
+			return true
+		}
+	}
+
+	shared := stringset.New(d.addrs.Slice()...)
+	shared.Intersect(p.addrs)
+	return shared.Len() > 0
+}
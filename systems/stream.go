@@ -0,0 +1,61 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// StreamedName is the JSON object written to a NameStream for each discovered name.
+type StreamedName struct {
+	Name      string     `json:"name"`
+	Domain    string     `json:"domain"`
+	Tag       string     `json:"tag"`
+	Source    string     `json:"source"`
+	Timestamp time.Time  `json:"timestamp"`
+	FirstSeen *time.Time `json:"first_seen,omitempty"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+}
+
+// NameStream writes the names discovered by the data sources as JSON Lines, as soon as they are found.
+type NameStream struct {
+	sync.Mutex
+	enc *json.Encoder
+}
+
+// NewNameStream returns a NameStream that writes to the provided io.Writer.
+func NewNameStream(w io.Writer) *NameStream {
+	return &NameStream{enc: json.NewEncoder(w)}
+}
+
+// Write sends the name of the DNS request, along with the data source that found it, to the
+// stream as a single line. It is safe to call from the goroutines of every data source.
+func (s *NameStream) Write(req *requests.DNSRequest) error {
+	line := &StreamedName{
+		Name:      req.Name,
+		Domain:    req.Domain,
+		Tag:       req.Tag,
+		Source:    req.Source,
+		Timestamp: time.Now().UTC(),
+	}
+	// The dates are only included when the data source provided them
+	if !req.FirstSeen.IsZero() {
+		first := req.FirstSeen
+		line.FirstSeen = &first
+	}
+	if !req.LastSeen.IsZero() {
+		last := req.LastSeen
+		line.LastSeen = &last
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	return s.enc.Encode(line)
+}